@@ -0,0 +1,223 @@
+package werror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// Adapter extracts parameters from errors that are not werrors. It returns false if it does not apply to the provided
+// error.
+type Adapter func(err error) ([]Param, bool)
+
+// AdapterFor returns an Adapter that applies to errors whose chain contains an error of type E, as determined by
+// errors.As. The provided function is invoked with the first such error in the chain.
+func AdapterFor[E error](fn func(E) []Param) Adapter {
+	return func(err error) ([]Param, bool) {
+		target, ok := as[E](err)
+		if !ok {
+			return nil, false
+		}
+		return fn(target), true
+	}
+}
+
+// as is equivalent to errors.As, but only allocates if an error in the chain of err implements an As method. Since
+// Convert consults every registered adapter, this avoids an allocation per adapter for every converted error.
+func as[E error](err error) (E, bool) {
+	for err != nil {
+		if target, ok := err.(E); ok {
+			return target, true
+		}
+		switch x := err.(type) {
+		case interface{ As(interface{}) bool }:
+			var target E
+			ok := errors.As(err, &target)
+			return target, ok
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range x.Unwrap() {
+				if target, ok := as[E](wrapped); ok {
+					return target, true
+				}
+			}
+			err = nil
+		default:
+			err = nil
+		}
+	}
+	var zero E
+	return zero, false
+}
+
+var (
+	adaptersMu    sync.RWMutex
+	adapters      = defaultAdapters()
+	nextAdapterID int
+)
+
+type registeredAdapter struct {
+	id      int
+	adapter Adapter
+}
+
+// RegisterAdapter registers an adapter that is consulted by Convert and ConvertWithContextParams. Every registered
+// adapter that applies to an error contributes parameters; adapters registered later take precedence over adapters
+// registered earlier (including the default adapters) if they return parameters with the same key. The returned
+// function unregisters the adapter; calling it more than once has no effect.
+//
+// The default adapters extract the operation, system call, errno, exit code, offset and target type of standard
+// library errors as safe parameters, and the paths, input values and network addresses as unsafe parameters.
+func RegisterAdapter(adapter Adapter) (unregister func()) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	nextAdapterID++
+	id := nextAdapterID
+	adapters = append(adapters, registeredAdapter{id: id, adapter: adapter})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			adaptersMu.Lock()
+			defer adaptersMu.Unlock()
+			var remaining []registeredAdapter
+			for _, a := range adapters {
+				if a.id != id {
+					remaining = append(remaining, a)
+				}
+			}
+			adapters = remaining
+		})
+	}
+}
+
+// adaptedParams returns the parameters extracted from err by all of the registered adapters that apply to it.
+func adaptedParams(err error) []Param {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	var params []Param
+	for _, a := range adapters {
+		if adapterParams, ok := a.adapter(err); ok {
+			for _, p := range adapterParams {
+				params = append(params, adaptedParam{p})
+			}
+		}
+	}
+	return params
+}
+
+// adaptedParam marks the params stored by the wrapped Param as adapted, so that they are not formatted.
+type adaptedParam struct {
+	Param
+}
+
+func (p adaptedParam) apply(z *werror) {
+	if stored, ok := p.Param.(storedParam); ok {
+		stored.adapted = true
+		z.setParamValue(stored.key, stored.paramValue)
+		return
+	}
+	scratch := &werror{}
+	p.Param.apply(scratch)
+	for _, stored := range scratch.params {
+		stored.adapted = true
+		z.setParamValue(stored.key, stored.paramValue)
+	}
+}
+
+// ConvertWithContextParams is like Convert, but the returned error also includes any wparams parameters that are
+// stored in the context. If err is already a werror-based error, it is returned unchanged.
+func ConvertWithContextParams(ctx context.Context, err error) error {
+	if err == nil {
+		return err
+	}
+	switch err.(type) {
+	case Werror:
+		return err
+	default:
+		return newWerrorWithContext(ctx, "", err, adaptedParams(err)...)
+	}
+}
+
+func defaultAdapters() []registeredAdapter {
+	var registered []registeredAdapter
+	for _, adapter := range []Adapter{
+		AdapterFor(func(err syscall.Errno) []Param {
+			return []Param{
+				SafeParam("errno", int(err)),
+			}
+		}),
+		AdapterFor(func(err *fs.PathError) []Param {
+			return []Param{
+				SafeParam("op", err.Op),
+				UnsafeParam("path", err.Path),
+			}
+		}),
+		AdapterFor(func(err *os.LinkError) []Param {
+			return []Param{
+				SafeParam("op", err.Op),
+				UnsafeParam("oldPath", err.Old),
+				UnsafeParam("newPath", err.New),
+			}
+		}),
+		AdapterFor(func(err *os.SyscallError) []Param {
+			return []Param{
+				SafeParam("syscall", err.Syscall),
+			}
+		}),
+		AdapterFor(func(err *exec.ExitError) []Param {
+			return []Param{
+				SafeParam("exitCode", err.ExitCode()),
+			}
+		}),
+		AdapterFor(func(err *strconv.NumError) []Param {
+			return []Param{
+				SafeParam("func", err.Func),
+				UnsafeParam("input", err.Num),
+			}
+		}),
+		AdapterFor(func(err *json.SyntaxError) []Param {
+			return []Param{
+				SafeParam("offset", err.Offset),
+			}
+		}),
+		AdapterFor(func(err *json.UnmarshalTypeError) []Param {
+			params := []Param{
+				SafeParam("offset", err.Offset),
+				// the value describes the input, for example "number 42", so it is not safe
+				UnsafeParam("jsonValue", err.Value),
+			}
+			if err.Type != nil {
+				params = append(params, SafeParam("targetType", err.Type.String()))
+			}
+			if err.Field != "" {
+				params = append(params, SafeParam("field", err.Field))
+			}
+			return params
+		}),
+		AdapterFor(func(err *net.OpError) []Param {
+			params := []Param{
+				SafeParam("op", err.Op),
+				SafeParam("network", err.Net),
+			}
+			if err.Source != nil {
+				params = append(params, UnsafeParam("sourceAddress", err.Source.String()))
+			}
+			if err.Addr != nil {
+				params = append(params, UnsafeParam("address", err.Addr.String()))
+			}
+			return params
+		}),
+	} {
+		registered = append(registered, registeredAdapter{adapter: adapter})
+	}
+	return registered
+}
@@ -0,0 +1,228 @@
+package werror_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert_Adapters(t *testing.T) {
+	_, numErr := strconv.Atoi("not-a-number")
+	var jsonValue struct {
+		Count int `json:"count"`
+	}
+	typeErr := json.Unmarshal([]byte(`{"count":"three"}`), &jsonValue)
+	syntaxErr := json.Unmarshal([]byte(`{"count":`), &jsonValue)
+	numberTypeErr := json.Unmarshal([]byte(`{"count":12.345}`), &jsonValue)
+
+	for _, currCase := range []struct {
+		name       string
+		err        error
+		wantSafe   map[string]interface{}
+		wantUnsafe map[string]interface{}
+	}{
+		{
+			name: "path error",
+			err:  &os.PathError{Op: "open", Path: "/secret/file.txt", Err: syscall.ENOENT},
+			wantSafe: map[string]interface{}{
+				"op":    "open",
+				"errno": int(syscall.ENOENT),
+			},
+			wantUnsafe: map[string]interface{}{
+				"path": "/secret/file.txt",
+			},
+		},
+		{
+			name: "link error",
+			err:  &os.LinkError{Op: "rename", Old: "/a", New: "/b", Err: errors.New("failed")},
+			wantSafe: map[string]interface{}{
+				"op": "rename",
+			},
+			wantUnsafe: map[string]interface{}{
+				"oldPath": "/a",
+				"newPath": "/b",
+			},
+		},
+		{
+			name: "syscall error",
+			err:  os.NewSyscallError("fsync", syscall.EIO),
+			wantSafe: map[string]interface{}{
+				"syscall": "fsync",
+				"errno":   int(syscall.EIO),
+			},
+			wantUnsafe: map[string]interface{}{},
+		},
+		{
+			name: "num error wrapped with fmt",
+			err:  fmt.Errorf("parsing config: %w", numErr),
+			wantSafe: map[string]interface{}{
+				"func": "Atoi",
+			},
+			wantUnsafe: map[string]interface{}{
+				"input": "not-a-number",
+			},
+		},
+		{
+			name: "num error joined",
+			err:  errors.Join(errors.New("other"), numErr),
+			wantSafe: map[string]interface{}{
+				"func": "Atoi",
+			},
+			wantUnsafe: map[string]interface{}{
+				"input": "not-a-number",
+			},
+		},
+		{
+			name: "num error provided by As method",
+			err:  &asTestError{numErr: numErr.(*strconv.NumError)},
+			wantSafe: map[string]interface{}{
+				"func": "Atoi",
+			},
+			wantUnsafe: map[string]interface{}{
+				"input": "not-a-number",
+			},
+		},
+		{
+			name: "json syntax error",
+			err:  syntaxErr,
+			wantSafe: map[string]interface{}{
+				"offset": int64(9),
+			},
+			wantUnsafe: map[string]interface{}{},
+		},
+		{
+			name: "json type error",
+			err:  typeErr,
+			wantSafe: map[string]interface{}{
+				"offset":     int64(16),
+				"targetType": "int",
+				"field":      "count",
+			},
+			wantUnsafe: map[string]interface{}{
+				"jsonValue": "string",
+			},
+		},
+		{
+			name: "json type error with number literal",
+			err:  numberTypeErr,
+			wantSafe: map[string]interface{}{
+				"offset":     int64(15),
+				"targetType": "int",
+				"field":      "count",
+			},
+			wantUnsafe: map[string]interface{}{
+				"jsonValue": "number 12.345",
+			},
+		},
+		{
+			name: "net op error",
+			err: &net.OpError{
+				Op:   "dial",
+				Net:  "tcp",
+				Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443},
+				Err:  syscall.ECONNREFUSED,
+			},
+			wantSafe: map[string]interface{}{
+				"op":      "dial",
+				"network": "tcp",
+				"errno":   int(syscall.ECONNREFUSED),
+			},
+			wantUnsafe: map[string]interface{}{
+				"address": "10.0.0.1:443",
+			},
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			converted := werror.Convert(currCase.err)
+			assert.EqualError(t, converted, currCase.err.Error())
+			// the adapted params are not formatted, so the converted error is formatted like the error it converts
+			assert.Equal(t, fmt.Sprintf("%v", currCase.err), fmt.Sprintf("%v", converted))
+			assert.True(t, errors.Is(converted, currCase.err))
+			safe, unsafe := werror.ParamsFromError(converted)
+			assert.Equal(t, currCase.wantSafe, safe)
+			assert.Equal(t, currCase.wantUnsafe, unsafe)
+		})
+	}
+}
+
+// asTestError provides a *strconv.NumError using an As method rather than by wrapping it.
+type asTestError struct {
+	numErr *strconv.NumError
+}
+
+func (e *asTestError) Error() string {
+	return "as test error"
+}
+
+func (e *asTestError) As(target interface{}) bool {
+	if numErr, ok := target.(**strconv.NumError); ok {
+		*numErr = e.numErr
+		return true
+	}
+	return false
+}
+
+type adapterTestError struct {
+	code int
+}
+
+func (e *adapterTestError) Error() string {
+	return "adapter test error"
+}
+
+func TestRegisterAdapter(t *testing.T) {
+	unregister := werror.RegisterAdapter(werror.AdapterFor(func(err *adapterTestError) []werror.Param {
+		return []werror.Param{
+			werror.SafeParam("code", err.code),
+			werror.UnsafeParams(map[string]interface{}{"detail": "secret"}),
+		}
+	}))
+	defer unregister()
+
+	err := fmt.Errorf("wrapped: %w", &adapterTestError{code: 42})
+	converted := werror.Convert(err)
+	code, safe := werror.ParamFromError(converted, "code")
+	assert.Equal(t, 42, code)
+	assert.True(t, safe)
+	detail, safe := werror.ParamFromError(converted, "detail")
+	assert.Equal(t, "secret", detail)
+	assert.False(t, safe)
+	assert.Equal(t, "wrapped: adapter test error", fmt.Sprintf("%v", converted))
+	assert.Contains(t, werror.GenerateErrorString(converted, false), "code:42")
+
+	// params that are not extracted by adapters are formatted
+	ctx := wparams.ContextWithSafeParam(context.Background(), "requestId", "abc")
+	assert.Equal(t, "map[requestId:abc]: wrapped: adapter test error", fmt.Sprintf("%v", werror.ConvertWithContextParams(ctx, err)))
+
+	unregister()
+	unregister()
+	_, ok := werror.ParamFromError(werror.Convert(err), "code")
+	assert.False(t, ok)
+}
+
+func TestConvertWithContextParams(t *testing.T) {
+	ctx := wparams.ContextWithSafeAndUnsafeParams(context.Background(),
+		map[string]interface{}{"requestId": "abc"},
+		map[string]interface{}{"user": "bob"},
+	)
+	converted := werror.ConvertWithContextParams(ctx, &os.PathError{Op: "open", Path: "/file", Err: errors.New("failed")})
+	safe, unsafe := werror.ParamsFromError(converted)
+	assert.Equal(t, map[string]interface{}{"requestId": "abc", "op": "open"}, safe)
+	assert.Equal(t, map[string]interface{}{"user": "bob", "path": "/file"}, unsafe)
+	assert.Contains(t, fmt.Sprintf("%+v", converted.(werror.Werror).StackTrace()), "TestConvertWithContextParams")
+
+	werr := werror.ErrorWithContextParams(context.Background(), "already a werror")
+	assert.Equal(t, werr, werror.ConvertWithContextParams(ctx, werr))
+	require.Nil(t, werror.ConvertWithContextParams(ctx, nil))
+}
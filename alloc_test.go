@@ -0,0 +1,123 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	benchmarkErr  = errors.New("benchmark error")
+	benchmarkSink error
+)
+
+func BenchmarkError(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink = werror.Error("error")
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	b.Run("without params", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkSink = werror.Wrap(benchmarkErr, "wrapped")
+		}
+	})
+	b.Run("with param", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkSink = werror.Wrap(benchmarkErr, "wrapped", werror.SafeParam("key", "value"))
+		}
+	})
+}
+
+func BenchmarkWrapWithContextParams(b *testing.B) {
+	b.Run("empty context", func(b *testing.B) {
+		ctx := context.Background()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkSink = werror.WrapWithContextParams(ctx, benchmarkErr, "wrapped")
+		}
+	})
+	b.Run("context with params", func(b *testing.B) {
+		ctx := wparams.ContextWithSafeAndUnsafeParams(context.Background(),
+			map[string]interface{}{"requestId": "r1"},
+			map[string]interface{}{"userAgent": "curl"},
+		)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkSink = werror.WrapWithContextParams(ctx, benchmarkErr, "wrapped")
+		}
+	})
+}
+
+func BenchmarkConvert(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink = werror.Convert(benchmarkErr)
+	}
+}
+
+// BenchmarkErrorParallel creates errors from the same call site on every core, which shares the interned stack of the
+// call site between goroutines.
+func BenchmarkErrorParallel(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		var err error
+		for pb.Next() {
+			err = werror.WrapWithContextParams(ctx, benchmarkErr, "wrapped")
+		}
+		_ = err
+	})
+}
+
+func TestAllocations(t *testing.T) {
+	ctx := context.Background()
+	for _, currCase := range []struct {
+		name       string
+		fn         func()
+		wantAllocs float64
+	}{
+		{
+			name: "Error",
+			fn: func() {
+				benchmarkSink = werror.Error("error")
+			},
+			wantAllocs: 1,
+		},
+		{
+			name: "Wrap",
+			fn: func() {
+				benchmarkSink = werror.Wrap(benchmarkErr, "wrapped")
+			},
+			wantAllocs: 1,
+		},
+		{
+			name: "WrapWithContextParams",
+			fn: func() {
+				benchmarkSink = werror.WrapWithContextParams(ctx, benchmarkErr, "wrapped")
+			},
+			wantAllocs: 1,
+		},
+		{
+			name: "Convert",
+			fn: func() {
+				benchmarkSink = werror.Convert(benchmarkErr)
+			},
+			wantAllocs: 1,
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			// the first call interns the stack of the call site
+			currCase.fn()
+			assert.Equal(t, currCase.wantAllocs, testing.AllocsPerRun(100, currCase.fn))
+		})
+	}
+}
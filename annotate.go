@@ -0,0 +1,38 @@
+package werror
+
+import (
+	"context"
+)
+
+// WithParams returns an error that annotates err with the provided parameters without adding a message or capturing
+// a stack trace. Returns nil if err is nil.
+//
+// It is intended to attach context to an error as it passes through a layer that has nothing to add to the message.
+// The returned error behaves like err for Error() and the parameters are collapsed into the adjacent level by
+// GenerateErrorString and the fmt verbs.
+//
+// Example:
+//
+//	if err := store.Put(ctx, key, value); err != nil {
+//		return werror.WithParams(err, werror.SafeParam("storeName", name))
+//	}
+func WithParams(err error, params ...Param) error {
+	if err == nil {
+		return nil
+	}
+	return newWerrorWithStack(nil, "", err, nil, params...)
+}
+
+// WithContextParams is like WithParams, but the returned error also includes any wparams parameters that are stored in
+// the context.
+func WithContextParams(ctx context.Context, err error, params ...Param) error {
+	if err == nil {
+		return nil
+	}
+	return newWerrorWithStack(ctx, "", err, nil, params...)
+}
+
+// isAnnotation returns true if the provided error is an annotation level created by WithParams or WithContextParams.
+func isAnnotation(err Werror) bool {
+	return err.Message() == "" && err.StackTrace() == nil
+}
@@ -0,0 +1,87 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithParams(t *testing.T) {
+	inner := werror.ErrorWithContextParams(context.Background(), "inner", werror.SafeParam("innerKey", "innerValue"))
+	annotated := werror.WithParams(inner, werror.SafeParam("storeName", "users"), werror.UnsafeParam("key", "secret"))
+	require.Error(t, annotated)
+
+	assert.EqualError(t, annotated, "inner")
+	assert.True(t, errors.Is(annotated, inner))
+	werr, ok := annotated.(werror.Werror)
+	require.True(t, ok)
+	assert.Empty(t, werr.Message())
+	assert.Nil(t, werr.StackTrace())
+
+	safe, unsafe := werror.ParamsFromError(annotated)
+	assert.Equal(t, map[string]interface{}{"innerKey": "innerValue", "storeName": "users"}, safe)
+	assert.Equal(t, map[string]interface{}{"key": "secret"}, unsafe)
+
+	assert.Nil(t, werror.WithParams(nil, werror.SafeParam("key", "value")))
+}
+
+func TestWithContextParams(t *testing.T) {
+	ctx := wparams.ContextWithSafeParam(context.Background(), "requestId", "abc")
+	annotated := werror.WithContextParams(ctx, errors.New("plain"), werror.SafeParam("key", "value"))
+	safe, _ := werror.ParamsFromError(annotated)
+	assert.Equal(t, map[string]interface{}{"requestId": "abc", "key": "value"}, safe)
+	assert.EqualError(t, annotated, "plain")
+	assert.Nil(t, werror.WithContextParams(ctx, nil))
+}
+
+func TestWithParams_Formatting(t *testing.T) {
+	inner := werror.ErrorWithContextParams(context.Background(), "inner", werror.SafeParam("innerKey", "innerValue"))
+	outer := werror.WrapWithContextParams(context.Background(),
+		werror.WithParams(werror.WithParams(inner, werror.SafeParam("first", 1)), werror.SafeParam("second", 2)),
+		"outer",
+	)
+
+	t.Run("GenerateErrorString collapses annotations into their cause", func(t *testing.T) {
+		printed := werror.GenerateErrorString(outer, false)
+		assert.True(t, strings.HasPrefix(printed, "outer\ninner first:1, innerKey:innerValue, second:2\n\n"), printed)
+		assert.Contains(t, printed, "TestWithParams_Formatting")
+
+		printed = werror.GenerateErrorString(werror.WithParams(errors.New("plain"), werror.SafeParam("key", "value")), false)
+		assert.Equal(t, "key:value\nplain", printed)
+	})
+
+	t.Run("fmt verbs", func(t *testing.T) {
+		assert.Equal(t, "outer: inner", fmt.Sprintf("%s", outer))
+		assert.Equal(t, "outer: inner map[first:1 innerKey:innerValue second:2]", fmt.Sprintf("%v", outer))
+		assert.Equal(t, "inner map[first:1 innerKey:innerValue]", fmt.Sprintf("%v", werror.WithParams(inner, werror.SafeParam("first", 1))))
+		// annotations of errors that are not werrors are formatted as a separate level
+		assert.Equal(t, "map[key:value]: plain", fmt.Sprintf("%v", werror.WithParams(errors.New("plain"), werror.SafeParam("key", "value"))))
+		// an empty level without params does not print a separator
+		assert.Equal(t, "outer: inner map[innerKey:innerValue]", fmt.Sprintf("%v", werror.WrapWithContextParams(context.Background(), werror.WithParams(inner), "outer")))
+		assert.NotContains(t, fmt.Sprintf("%+v", outer), "%!")
+		assert.Contains(t, fmt.Sprintf("%+v", outer), "inner map[first:1 innerKey:innerValue second:2]")
+	})
+
+	t.Run("annotation params that collide with deeper params", func(t *testing.T) {
+		// the deepest value of a key wins, so every printer reports the value of the annotated level
+		annotated := werror.WithParams(werror.Error("inner", werror.SafeParam("k", "inner")), werror.SafeParam("k", "annotation"))
+		assert.Equal(t, map[string]interface{}{"k": "inner"}, annotated.(werror.Werror).SafeParams())
+		assert.Equal(t, "inner map[k:inner]", fmt.Sprintf("%v", annotated))
+		assert.True(t, strings.HasPrefix(werror.GenerateErrorString(annotated, false), "inner k:inner\n"))
+
+		// a deeper annotation wins over an outer one
+		annotated = werror.WithParams(werror.WithParams(inner, werror.SafeParam("k", "deeper")), werror.SafeParam("k", "outer"))
+		assert.Equal(t, "inner map[innerKey:innerValue k:deeper]", fmt.Sprintf("%v", annotated))
+
+		// an unsafe deeper value hides the safe annotation value
+		annotated = werror.WithParams(werror.Error("inner", werror.UnsafeParam("k", "secret")), werror.SafeParam("k", "annotation"))
+		assert.Equal(t, "inner", fmt.Sprintf("%v", annotated))
+	})
+}
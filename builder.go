@@ -0,0 +1,86 @@
+package werror
+
+import (
+	"context"
+)
+
+// Builder builds werrors using a fluent API. Builders are immutable: every method returns a new Builder, so a partially
+// configured Builder can be shared and reused.
+//
+// Example:
+//
+//	return werror.Build(ctx).
+//		Safe("userId", userID).
+//		Unsafe("email", email).
+//		Type(werror.DefaultNotFound).
+//		Wrap(err, "failed to get user")
+type Builder struct {
+	ctx    context.Context
+	params []Param
+	skip   int
+}
+
+// Build returns a new Builder for errors that include the wparams parameters stored in the provided context.
+func Build(ctx context.Context) Builder {
+	return Builder{ctx: ctx}
+}
+
+// Safe returns a Builder that adds the provided safe parameter.
+func (b Builder) Safe(key string, value interface{}) Builder {
+	return b.with(SafeParam(key, value))
+}
+
+// Unsafe returns a Builder that adds the provided unsafe parameter.
+func (b Builder) Unsafe(key string, value interface{}) Builder {
+	return b.with(UnsafeParam(key, value))
+}
+
+// Params returns a Builder that adds the provided Params.
+func (b Builder) Params(params ...Param) Builder {
+	return b.with(params...)
+}
+
+// Type returns a Builder that declares the provided error type. See Type.
+func (b Builder) Type(errorType ErrorType) Builder {
+	return b.with(Type(errorType))
+}
+
+// Retryable returns a Builder that declares whether the operation that produced the error can be retried. See
+// Retryable.
+func (b Builder) Retryable(retryable bool) Builder {
+	return b.with(Retryable(retryable))
+}
+
+// SkipFrames returns a Builder that skips an additional skip stack frames when capturing the stack trace of the error.
+// It is typically used by helper functions that build errors on behalf of their callers.
+func (b Builder) SkipFrames(skip int) Builder {
+	b.skip += skip
+	return b
+}
+
+// New returns a new error with the provided message.
+func (b Builder) New(msg string) error {
+	return newWerrorWithStack(b.context(), msg, nil, NewStackTraceWithSkip(b.skip), b.params...)
+}
+
+// Wrap returns a new error with the provided message that stores the provided error as its cause. Returns nil if err
+// is nil.
+func (b Builder) Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return newWerrorWithStack(b.context(), msg, err, NewStackTraceWithSkip(b.skip), b.params...)
+}
+
+func (b Builder) with(params ...Param) Builder {
+	// use a full slice expression so that builders derived from the same builder never share appended elements
+	b.params = append(b.params[:len(b.params):len(b.params)], params...)
+	return b
+}
+
+func (b Builder) context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
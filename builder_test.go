@@ -0,0 +1,102 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	ctx := wparams.ContextWithSafeAndUnsafeParams(context.Background(),
+		map[string]interface{}{"requestId": "r1"},
+		map[string]interface{}{"user": "bob"},
+	)
+	cause := errors.New("no rows")
+	err := werror.Build(ctx).
+		Safe("userId", "u1").
+		Unsafe("email", "bob@example.com").
+		Params(werror.SafeParam("attempt", 2)).
+		Type(werror.DefaultNotFound).
+		Retryable(true).
+		Wrap(cause, "failed to get user")
+
+	assert.EqualError(t, err, "failed to get user: no rows")
+	assert.True(t, errors.Is(err, cause))
+	safe, unsafe := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{"requestId": "r1", "userId": "u1", "attempt": 2}, safe)
+	assert.Equal(t, map[string]interface{}{"user": "bob", "email": "bob@example.com"}, unsafe)
+	errorType, ok := werror.TypeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, werror.DefaultNotFound, errorType)
+	assert.True(t, werror.IsRetryable(err))
+}
+
+func TestBuilderIsImmutable(t *testing.T) {
+	parent := werror.Build(context.Background()).Safe("shared", "value")
+	first := parent.Safe("first", 1)
+	second := parent.Safe("second", 2).Retryable(true)
+
+	for _, currCase := range []struct {
+		name          string
+		err           error
+		wantSafe      map[string]interface{}
+		wantRetryable bool
+	}{
+		{
+			name:     "parent",
+			err:      parent.New("parent"),
+			wantSafe: map[string]interface{}{"shared": "value"},
+		},
+		{
+			name:     "first",
+			err:      first.New("first"),
+			wantSafe: map[string]interface{}{"shared": "value", "first": 1},
+		},
+		{
+			name:          "second",
+			err:           second.New("second"),
+			wantSafe:      map[string]interface{}{"shared": "value", "second": 2},
+			wantRetryable: true,
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			safe, _ := werror.ParamsFromError(currCase.err)
+			assert.Equal(t, currCase.wantSafe, safe)
+			assert.Equal(t, currCase.wantRetryable, werror.IsRetryable(currCase.err))
+		})
+	}
+}
+
+func TestBuilderNilContext(t *testing.T) {
+	var ctx context.Context
+	err := werror.Build(ctx).Safe("key", "value").New("message")
+	assert.EqualError(t, err, "message")
+	value, safe := werror.ParamFromError(err, "key")
+	assert.Equal(t, "value", value)
+	assert.True(t, safe)
+}
+
+func TestBuilderWrapNilErrorIsNil(t *testing.T) {
+	assert.Nil(t, werror.Build(context.Background()).Safe("key", "value").Wrap(nil, "message"))
+}
+
+// newErrorInHelper creates an error on behalf of its caller.
+func newErrorInHelper(msg string) error {
+	return werror.Build(context.Background()).SkipFrames(1).New(msg)
+}
+
+func TestBuilderSkipFrames(t *testing.T) {
+	err := newErrorInHelper("from helper")
+	stacktrace := fmt.Sprintf("%+v", err.(werror.Werror).StackTrace())
+	assert.NotContains(t, stacktrace, "newErrorInHelper")
+	assert.Contains(t, stacktrace, "TestBuilderSkipFrames")
+
+	err = werror.Build(context.Background()).New("direct")
+	assert.Contains(t, fmt.Sprintf("%+v", err.(werror.Werror).StackTrace()), "TestBuilderSkipFrames")
+}
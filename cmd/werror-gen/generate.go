@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// categoryConstants maps the categories to the names of their constants in the werror package.
+var categoryConstants = map[werror.Category]string{
+	werror.CategoryPermissionDenied:      "CategoryPermissionDenied",
+	werror.CategoryInvalidArgument:       "CategoryInvalidArgument",
+	werror.CategoryNotFound:              "CategoryNotFound",
+	werror.CategoryConflict:              "CategoryConflict",
+	werror.CategoryRequestEntityTooLarge: "CategoryRequestEntityTooLarge",
+	werror.CategoryFailedPrecondition:    "CategoryFailedPrecondition",
+	werror.CategoryInternal:              "CategoryInternal",
+	werror.CategoryTimeout:               "CategoryTimeout",
+	werror.CategoryCustomClient:          "CategoryCustomClient",
+	werror.CategoryCustomServer:          "CategoryCustomServer",
+}
+
+// Generate returns the formatted Go source for the errors in the provided spec. The spec must have been validated by
+// ParseSpec.
+func Generate(spec Spec, pkg string) ([]byte, error) {
+	if pkg == "" {
+		pkg = spec.Package
+	}
+	data := templateData{Package: pkg}
+	for _, errSpec := range spec.Errors {
+		errData := errorData{
+			GoName:    errSpec.Name,
+			ErrorName: errSpec.errorName(),
+			Category:  categoryConstants[werror.Category(errSpec.Code)],
+			Docs:      docLines(errSpec.Docs),
+		}
+		usedNames := map[string]struct{}{"ctx": {}, "err": {}, "params": {}}
+		for _, arg := range errSpec.args() {
+			argType, err := goType(arg.Type)
+			if err != nil {
+				return nil, err
+			}
+			if strings.Contains(argType, "time.Time") {
+				data.ImportTime = true
+			}
+			varName := goIdentifier(arg.Name, false)
+			if _, ok := usedNames[varName]; ok || token.IsKeyword(varName) {
+				varName += "Arg"
+			}
+			usedNames[varName] = struct{}{}
+			errData.Args = append(errData.Args, argData{
+				Key:       arg.Name,
+				FieldName: goIdentifier(arg.Name, true),
+				VarName:   varName,
+				Type:      argType,
+				Safe:      len(errData.Args) < len(errSpec.SafeArgs),
+				Docs:      docLines(arg.Docs),
+			})
+		}
+		data.Errors = append(data.Errors, errData)
+	}
+
+	var buf bytes.Buffer
+	if err := outputTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+type templateData struct {
+	Package    string
+	ImportTime bool
+	Errors     []errorData
+}
+
+type errorData struct {
+	GoName    string
+	ErrorName string
+	Category  string
+	Docs      []string
+	Args      []argData
+}
+
+type argData struct {
+	Key       string
+	FieldName string
+	VarName   string
+	Type      string
+	Safe      bool
+	Docs      []string
+}
+
+func docLines(docs string) []string {
+	docs = strings.TrimSpace(docs)
+	if docs == "" {
+		return nil
+	}
+	return strings.Split(docs, "\n")
+}
+
+// initialisms are the words that are upper-cased in Go identifiers.
+var initialisms = map[string]struct{}{
+	"api": {}, "http": {}, "id": {}, "ip": {}, "json": {}, "rid": {}, "sql": {}, "uri": {}, "url": {}, "uuid": {},
+}
+
+// goIdentifier converts the provided lowerCamelCase name to a Go identifier that follows the Go conventions for
+// initialisms, for example "userId" to "UserID" (exported) or "userID" (unexported).
+func goIdentifier(name string, exported bool) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+
+	var sb strings.Builder
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		switch {
+		case i == 0 && !exported:
+			sb.WriteString(lower)
+		case isInitialism(lower):
+			sb.WriteString(strings.ToUpper(lower))
+		case strings.HasSuffix(lower, "s") && isInitialism(strings.TrimSuffix(lower, "s")):
+			// plural initialisms such as "Ids" become "IDs"
+			sb.WriteString(strings.ToUpper(strings.TrimSuffix(lower, "s")) + "s")
+		default:
+			sb.WriteString(strings.ToUpper(lower[:1]) + lower[1:])
+		}
+	}
+	return sb.String()
+}
+
+func isInitialism(word string) bool {
+	_, ok := initialisms[word]
+	return ok
+}
+
+var outputTemplate = template.Must(template.New("errors").Parse(`// Code generated by werror-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+{{- if .ImportTime }}
+	"time"
+{{- end }}
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+{{ range .Errors }}
+{{- $name := .GoName }}
+// {{ $name }}Type is the type of {{ .ErrorName }} errors.
+var {{ $name }}Type = werror.MustErrorType(werror.{{ .Category }}, "{{ .ErrorName }}")
+
+// {{ $name }}Params are the params of {{ .ErrorName }} errors.
+type {{ $name }}Params struct {
+{{- range .Args }}
+{{- range .Docs }}
+	// {{ . }}
+{{- end }}
+	{{ .FieldName }} {{ .Type }} ` + "`" + `werror:"{{ .Key }},{{ if .Safe }}safe{{ else }}unsafe{{ end }}"` + "`" + `
+{{- end }}
+}
+
+// {{ $name }} is the definition of {{ .ErrorName }} errors.
+var {{ $name }} = werror.Define[{{ $name }}Params]({{ $name }}Type.Name(), {{ $name }}Type.Category())
+
+// New{{ $name }} returns a new {{ .ErrorName }} error.
+{{- if .Docs }}
+//
+{{- range .Docs }}
+// {{ . }}
+{{- end }}
+{{- end }}
+func New{{ $name }}(ctx context.Context{{ range .Args }}, {{ .VarName }} {{ .Type }}{{ end }}, params ...werror.Param) error {
+	return {{ $name }}.New(ctx, {{ $name }}Params{
+{{- range .Args }}
+		{{ .FieldName }}: {{ .VarName }},
+{{- end }}
+	}, params...)
+}
+
+// Wrap{{ $name }} returns a new {{ .ErrorName }} error that wraps the provided error.
+// Returns nil if err is nil.
+func Wrap{{ $name }}(ctx context.Context, err error{{ range .Args }}, {{ .VarName }} {{ .Type }}{{ end }}, params ...werror.Param) error {
+	return {{ $name }}.Wrap(ctx, err, {{ $name }}Params{
+{{- range .Args }}
+		{{ .FieldName }}: {{ .VarName }},
+{{- end }}
+	}, params...)
+}
+
+// Is{{ $name }} returns true if the provided error or any of its causes is a {{ .ErrorName }} error.
+func Is{{ $name }}(err error) bool {
+	return {{ $name }}.Is(err)
+}
+
+// {{ $name }}ParamsFromError returns the params of the outermost {{ .ErrorName }} error
+// in the chain of the provided error. Returns false if there is no such error.
+func {{ $name }}ParamsFromError(err error) ({{ $name }}Params, bool) {
+	return {{ $name }}.Params(err)
+}
+{{ end }}
+func init() {
+{{- range .Errors }}
+	werror.RegisterErrorType({{ .GoName }}Type)
+{{- end }}
+}
+`))
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerate(t *testing.T) {
+	specFiles, err := filepath.Glob(filepath.Join("testdata", "*.yml"))
+	require.NoError(t, err)
+	jsonSpecFiles, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	specFiles = append(specFiles, jsonSpecFiles...)
+	require.NotEmpty(t, specFiles)
+
+	for _, specFile := range specFiles {
+		t.Run(filepath.Base(specFile), func(t *testing.T) {
+			data, err := os.ReadFile(specFile)
+			require.NoError(t, err)
+			spec, err := ParseSpec(data)
+			require.NoError(t, err)
+			got, err := Generate(spec, "")
+			require.NoError(t, err)
+
+			goldenFile := strings.TrimSuffix(specFile, filepath.Ext(specFile)) + filepath.Ext(specFile) + ".go.golden"
+			if *update {
+				require.NoError(t, os.WriteFile(goldenFile, got, 0644))
+			}
+			want, err := os.ReadFile(goldenFile)
+			require.NoError(t, err, "run the tests with -update to create the golden file")
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	for _, currCase := range []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{
+			name: "invalid code",
+			spec: `errors:
+  - {namespace: Users, name: UserNotFound, code: MISSING}`,
+			wantErr: "invalid error Users:UserNotFound: invalid error category",
+		},
+		{
+			name: "invalid name",
+			spec: `errors:
+  - {namespace: users, name: UserNotFound, code: NOT_FOUND}`,
+			wantErr: "invalid error users:UserNotFound: error name must be of the form Namespace:Name with UpperCamelCase components",
+		},
+		{
+			name: "duplicate error",
+			spec: `errors:
+  - {namespace: Users, name: UserNotFound, code: NOT_FOUND}
+  - {namespace: Groups, name: UserNotFound, code: NOT_FOUND}`,
+			wantErr: "duplicate error name UserNotFound",
+		},
+		{
+			name: "invalid argument name",
+			spec: `errors:
+  - {namespace: Users, name: UserNotFound, code: NOT_FOUND, safe-args: [{name: UserId, type: string}]}`,
+			wantErr: `invalid argument name "UserId" of error Users:UserNotFound: must be lowerCamelCase`,
+		},
+		{
+			name: "duplicate argument",
+			spec: `errors:
+  - namespace: Users
+    name: UserNotFound
+    code: NOT_FOUND
+    safe-args: [{name: userId, type: string}]
+    unsafe-args: [{name: userId, type: string}]`,
+			wantErr: "duplicate argument userId of error Users:UserNotFound",
+		},
+		{
+			name: "unsupported type",
+			spec: `errors:
+  - {namespace: Users, name: UserNotFound, code: NOT_FOUND, safe-args: [{name: userId, type: "map<string, string>"}]}`,
+			wantErr: `invalid type of argument userId of error Users:UserNotFound: unsupported type "map<string, string>"`,
+		},
+		{
+			name:    "unknown field",
+			spec:    `unknown: value`,
+			wantErr: "failed to parse spec: yaml: unmarshal errors:\n  line 1: field unknown not found in type main.Spec",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			_, err := ParseSpec([]byte(currCase.spec))
+			assert.EqualError(t, err, currCase.wantErr)
+		})
+	}
+}
+
+func TestGoIdentifier(t *testing.T) {
+	for in, want := range map[string][2]string{
+		"userId":   {"UserID", "userID"},
+		"groupIds": {"GroupIDs", "groupIDs"},
+		"url":      {"URL", "url"},
+		"httpCode": {"HTTPCode", "httpCode"},
+		"name":     {"Name", "name"},
+	} {
+		assert.Equal(t, want[0], goIdentifier(in, true), in)
+		assert.Equal(t, want[1], goIdentifier(in, false), in)
+	}
+}
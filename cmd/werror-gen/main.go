@@ -0,0 +1,65 @@
+// Command werror-gen generates Go code for the errors defined in a YAML or JSON spec file.
+//
+// For each error, the generated code contains:
+//
+//	<Name>Type                  the werror.ErrorType of the error
+//	<Name>Params                the params struct, whose fields are the safe and unsafe arguments of the error
+//	<Name>                      the werror.Definition of the error
+//	New<Name> / Wrap<Name>      constructors that take the arguments of the error
+//	Is<Name>                    returns true if an error chain contains the error
+//	<Name>ParamsFromError       returns the typed params of the error
+//
+// The generated file also registers all error types using werror.RegisterErrorType. See Spec for the format of the
+// spec file.
+//
+// Usage:
+//
+//	werror-gen [-package name] [-out file] spec.yml
+//
+// With no -out flag, the generated source is written to standard output.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var (
+		pkg = flag.String("package", "", "name of the generated package (overrides the package in the spec)")
+		out = flag.String("out", "", "file to which the generated source is written")
+	)
+	flag.Parse()
+	if flag.NArg() != 1 {
+		_, _ = fmt.Fprintln(os.Stderr, "usage: werror-gen [-package name] [-out file] spec.yml")
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *pkg, *out); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(specFile, pkg, out string) error {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return err
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", specFile, err)
+	}
+	if pkg == "" && spec.Package == "" {
+		return fmt.Errorf("%s: package must be specified in the spec or using the -package flag", specFile)
+	}
+	src, err := Generate(spec, pkg)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the definition of a set of errors. Since JSON is a subset of YAML, specs can be written in either format:
+//
+//	package: users
+//	errors:
+//	  - namespace: Users
+//	    name: UserNotFound
+//	    code: NOT_FOUND
+//	    docs: The requested user does not exist.
+//	    safe-args:
+//	      - name: userId
+//	        type: string
+//	    unsafe-args:
+//	      - name: email
+//	        type: optional<string>
+type Spec struct {
+	// Package is the name of the generated Go package. Can be overridden using the -package flag.
+	Package string      `yaml:"package"`
+	Errors  []ErrorSpec `yaml:"errors"`
+}
+
+// ErrorSpec is the definition of a single error.
+type ErrorSpec struct {
+	Namespace  string    `yaml:"namespace"`
+	Name       string    `yaml:"name"`
+	Code       string    `yaml:"code"`
+	Docs       string    `yaml:"docs"`
+	SafeArgs   []ArgSpec `yaml:"safe-args"`
+	UnsafeArgs []ArgSpec `yaml:"unsafe-args"`
+}
+
+// ArgSpec is the definition of an argument of an error. The type is one of the Conjure primitive types "string",
+// "integer", "safelong", "double", "boolean", "datetime", "uuid", "rid", "bearertoken" or "any", or one of the
+// container types "optional<T>" and "list<T>".
+type ArgSpec struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	Docs string `yaml:"docs"`
+}
+
+var argNameRegexp = regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`)
+
+// ParseSpec parses and validates the provided YAML or JSON spec.
+func ParseSpec(data []byte) (Spec, error) {
+	var spec Spec
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("failed to parse spec: %v", err)
+	}
+	if err := spec.validate(); err != nil {
+		return Spec{}, err
+	}
+	return spec, nil
+}
+
+func (s Spec) validate() error {
+	if s.Package != "" && !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("invalid package name %q", s.Package)
+	}
+	names := make(map[string]struct{})
+	for _, errSpec := range s.Errors {
+		if _, err := werror.NewErrorType(werror.Category(errSpec.Code), errSpec.errorName()); err != nil {
+			return fmt.Errorf("invalid error %s: %s", errSpec.errorName(), err.Error())
+		}
+		if _, ok := names[errSpec.Name]; ok {
+			return fmt.Errorf("duplicate error name %s", errSpec.Name)
+		}
+		names[errSpec.Name] = struct{}{}
+
+		argNames := make(map[string]struct{})
+		for _, arg := range errSpec.args() {
+			if !argNameRegexp.MatchString(arg.Name) {
+				return fmt.Errorf("invalid argument name %q of error %s: must be lowerCamelCase", arg.Name, errSpec.errorName())
+			}
+			if _, ok := argNames[arg.Name]; ok {
+				return fmt.Errorf("duplicate argument %s of error %s", arg.Name, errSpec.errorName())
+			}
+			argNames[arg.Name] = struct{}{}
+			if _, err := goType(arg.Type); err != nil {
+				return fmt.Errorf("invalid type of argument %s of error %s: %v", arg.Name, errSpec.errorName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e ErrorSpec) errorName() string {
+	return e.Namespace + ":" + e.Name
+}
+
+func (e ErrorSpec) args() []ArgSpec {
+	return append(append([]ArgSpec(nil), e.SafeArgs...), e.UnsafeArgs...)
+}
+
+var primitiveGoTypes = map[string]string{
+	"string":      "string",
+	"integer":     "int",
+	"safelong":    "int64",
+	"double":      "float64",
+	"boolean":     "bool",
+	"datetime":    "time.Time",
+	"uuid":        "string",
+	"rid":         "string",
+	"bearertoken": "string",
+	"any":         "interface{}",
+}
+
+// goType returns the Go type for the provided spec type.
+func goType(specType string) (string, error) {
+	specType = strings.TrimSpace(specType)
+	if t, ok := primitiveGoTypes[specType]; ok {
+		return t, nil
+	}
+	for prefix, goPrefix := range map[string]string{"optional<": "*", "list<": "[]"} {
+		if strings.HasPrefix(specType, prefix) && strings.HasSuffix(specType, ">") {
+			elem, err := goType(specType[len(prefix) : len(specType)-1])
+			if err != nil {
+				return "", err
+			}
+			return goPrefix + elem, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %q", specType)
+}
@@ -0,0 +1,133 @@
+// Command werror-migrate rewrites error construction call sites to use the werror package.
+//
+// The following call sites are rewritten:
+//
+//	fmt.Errorf(format, args...)               -> werror.ErrorWithContextParams / werror.WrapWithContextParams
+//	errors.New(msg)                           -> werror.ErrorWithContextParams
+//	github.com/pkg/errors.{New,Errorf}        -> werror.ErrorWithContextParams
+//	github.com/pkg/errors.{Wrap,Wrapf}        -> werror.WrapWithContextParams
+//	github.com/pkg/errors.WithMessage{,f}     -> werror.WrapWithContextParams
+//	werror.Error / werror.Wrap (-deprecated)  -> werror.ErrorWithContextParams / werror.WrapWithContextParams
+//
+// Formatting verbs are removed from the message and their arguments become parameters. Parameters are unsafe unless
+// their key is provided using the -safe flag. If the enclosing function has a context.Context parameter, it is used as
+// the context argument; otherwise context.TODO() is used and the call site is reported so that it can be reviewed.
+// Calls outside of function bodies, such as the initializers of package-level sentinel errors, are not rewritten.
+//
+// Review the rewritten call sites whose cause may be nil: fmt.Errorf("...: %w", err) returns a non-nil error even if
+// err is nil, but werror.WrapWithContextParams returns nil if its cause is nil.
+//
+// Usage:
+//
+//	werror-migrate [flags] [path ...]
+//
+// With no -w or -l flag, the rewritten source is written to standard output.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		write      = flag.Bool("w", false, "write result to (source) file instead of stdout")
+		list       = flag.Bool("l", false, "list files whose contents would be rewritten")
+		safeKeys   = flag.String("safe", "", "comma-separated list of parameter keys that should be created as safe parameters")
+		deprecated = flag.Bool("deprecated", false, "also migrate the deprecated werror.Error and werror.Wrap functions")
+	)
+	flag.Parse()
+
+	cfg := Config{
+		SafeKeys:          splitKeys(*safeKeys),
+		MigrateDeprecated: *deprecated,
+	}
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	exitCode := 0
+	for _, path := range paths {
+		if err := walkGoFiles(path, func(filename string) error {
+			return processFile(filename, cfg, *write, *list)
+		}); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func splitKeys(keys string) []string {
+	var out []string
+	for _, k := range strings.Split(keys, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func walkGoFiles(root string, fn func(filename string) error) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fn(root)
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+func processFile(filename string, cfg Config, write, list bool) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	res, err := Migrate(filename, src, cfg)
+	if err != nil {
+		return err
+	}
+	for _, pos := range res.TODOContexts {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: no context.Context in scope, used context.TODO()\n", pos)
+	}
+	changed := !bytes.Equal(src, res.Source)
+	if list && changed {
+		fmt.Println(filename)
+	}
+	if write {
+		if !changed {
+			return nil
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, res.Source, info.Mode().Perm())
+	}
+	if !list {
+		_, err = os.Stdout.Write(res.Source)
+	}
+	return err
+}
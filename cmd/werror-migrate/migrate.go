@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	contextImportPath   = "context"
+	errorsImportPath    = "errors"
+	fmtImportPath       = "fmt"
+	pkgErrorsImportPath = "github.com/pkg/errors"
+	werrorImportPath    = "github.com/palantir/witchcraft-go-error"
+)
+
+// Config configures the rewrites performed by Migrate.
+type Config struct {
+	// SafeKeys is the allow-list of parameter keys that are created using werror.SafeParam. All other parameters are
+	// created using werror.UnsafeParam.
+	SafeKeys []string
+	// MigrateDeprecated specifies whether calls to the deprecated werror.Error and werror.Wrap functions should be
+	// rewritten to use their context-aware equivalents.
+	MigrateDeprecated bool
+}
+
+// Result is the result of migrating a single file.
+type Result struct {
+	// Source is the migrated source. It is identical to the input if no call sites were rewritten.
+	Source []byte
+	// TODOContexts contains the positions of the rewritten call sites for which no context.Context was in scope and
+	// context.TODO() was used instead.
+	TODOContexts []token.Position
+}
+
+// Migrate rewrites the error construction call sites in the provided Go source file as described in the package
+// documentation.
+func Migrate(filename string, src []byte, cfg Config) (Result, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return Result{}, err
+	}
+	m := &migrator{
+		fset:     fset,
+		file:     file,
+		cfg:      cfg,
+		safeKeys: make(map[string]struct{}),
+		imports:  make(map[string]string),
+	}
+	for _, k := range cfg.SafeKeys {
+		m.safeKeys[k] = struct{}{}
+	}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := defaultImportName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		m.imports[name] = path
+	}
+
+	ast.Walk(&scopeVisitor{m: m}, file)
+	if !m.changed {
+		return Result{Source: src}, nil
+	}
+	m.fixImports()
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return Result{}, err
+	}
+	out, err := groupImports(buf.Bytes())
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Source:       out,
+		TODOContexts: m.todoContexts,
+	}, nil
+}
+
+type migrator struct {
+	fset     *token.FileSet
+	file     *ast.File
+	cfg      Config
+	safeKeys map[string]struct{}
+	// imports maps the local name of each import to its path.
+	imports map[string]string
+
+	changed      bool
+	usedWerror   bool
+	usedContext  bool
+	todoContexts []token.Position
+}
+
+// scopeVisitor walks the AST and tracks the name of the context.Context in scope for the visited nodes.
+type scopeVisitor struct {
+	m   *migrator
+	ctx string
+	// inFunc is true if the visited nodes are inside a function. Calls outside of functions, such as the calls that
+	// initialize package-level sentinel errors, are not rewritten: they are evaluated once, so there is no context
+	// to pass to them and their result is typically compared to other errors.
+	inFunc bool
+}
+
+func (v *scopeVisitor) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		return &scopeVisitor{m: v.m, ctx: v.m.contextParam(n.Type), inFunc: true}
+	case *ast.FuncLit:
+		ctx := v.m.contextParam(n.Type)
+		if ctx == "" {
+			ctx = v.ctx
+		}
+		return &scopeVisitor{m: v.m, ctx: ctx, inFunc: true}
+	case *ast.CallExpr:
+		if v.inFunc {
+			v.m.rewrite(n, v.ctx)
+		}
+	}
+	return v
+}
+
+// contextParam returns the name of the first context.Context parameter of the provided function type, or the empty
+// string if there is no such parameter.
+func (m *migrator) contextParam(fnType *ast.FuncType) string {
+	if fnType.Params == nil {
+		return ""
+	}
+	for _, field := range fnType.Params.List {
+		if !m.isPkgSelector(field.Type, contextImportPath, "Context") {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				return name.Name
+			}
+		}
+	}
+	return ""
+}
+
+// rewrite rewrites the provided call in place if it is one of the supported error construction calls.
+func (m *migrator) rewrite(call *ast.CallExpr, ctx string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	path, ok := m.pkgPath(sel.X)
+	if !ok {
+		return
+	}
+	// the position must be resolved before the call is rewritten, since the rewritten call has no position
+	pos := m.fset.Position(call.Pos())
+	args := call.Args
+	var rewritten bool
+	switch path + "." + sel.Sel.Name {
+	case fmtImportPath + ".Errorf":
+		if len(args) >= 1 && !call.Ellipsis.IsValid() {
+			rewritten = m.rewriteFormat(call, ctx, nil, args[0], args[1:], true)
+		}
+	case errorsImportPath + ".New", pkgErrorsImportPath + ".New":
+		if len(args) == 1 {
+			m.setCall(call, ctx, "ErrorWithContextParams", args)
+			rewritten = true
+		}
+	case pkgErrorsImportPath + ".Errorf":
+		if len(args) >= 1 && !call.Ellipsis.IsValid() {
+			rewritten = m.rewriteFormat(call, ctx, nil, args[0], args[1:], false)
+		}
+	case pkgErrorsImportPath + ".Wrap", pkgErrorsImportPath + ".WithMessage":
+		if len(args) == 2 {
+			m.setCall(call, ctx, "WrapWithContextParams", args)
+			rewritten = true
+		}
+	case pkgErrorsImportPath + ".Wrapf", pkgErrorsImportPath + ".WithMessagef":
+		if len(args) >= 2 && !call.Ellipsis.IsValid() {
+			rewritten = m.rewriteFormat(call, ctx, args[0], args[1], args[2:], false)
+		}
+	case werrorImportPath + ".Error":
+		if m.cfg.MigrateDeprecated {
+			m.setCall(call, ctx, "ErrorWithContextParams", args)
+			rewritten = true
+		}
+	case werrorImportPath + ".Wrap":
+		if m.cfg.MigrateDeprecated {
+			m.setCall(call, ctx, "WrapWithContextParams", args)
+			rewritten = true
+		}
+	}
+	if rewritten && ctx == "" {
+		m.todoContexts = append(m.todoContexts, pos)
+	}
+}
+
+// rewriteFormat rewrites a call that uses a format string. If cause is nil and allowWrapVerb is true, the argument
+// for the "%w" verb is used as the cause. If cause is nil and there is no "%w" verb, the final argument is used as the
+// cause if it is formatted using "%v" or "%s" and its name indicates that it is an error. Returns false if the call
+// could not be rewritten.
+func (m *migrator) rewriteFormat(call *ast.CallExpr, ctx string, cause, formatArg ast.Expr, args []ast.Expr, allowWrapVerb bool) bool {
+	lit, ok := formatArg.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return false
+	}
+	literals, verbs, ok := parseFormat(format)
+	if !ok || len(verbs) != len(args) {
+		return false
+	}
+
+	causeIdx := -1
+	if cause == nil {
+		for i, verb := range verbs {
+			if verb == 'w' {
+				if !allowWrapVerb || causeIdx != -1 {
+					return false
+				}
+				causeIdx = i
+			}
+		}
+		if last := len(verbs) - 1; causeIdx == -1 && last >= 0 && (verbs[last] == 'v' || verbs[last] == 's') && isErrorLike(args[last]) {
+			causeIdx = last
+		}
+		if causeIdx != -1 {
+			cause = args[causeIdx]
+		}
+	}
+
+	var msg strings.Builder
+	var params []ast.Expr
+	keys := make(map[string]struct{})
+	for i, arg := range args {
+		text := literals[i]
+		if i == causeIdx {
+			msg.WriteString(text)
+			continue
+		}
+		key := ""
+		if match := keyPrefixRegexp.FindStringSubmatchIndex(text); match != nil {
+			key = text[match[2]:match[3]]
+			text = text[:match[0]]
+		}
+		msg.WriteString(text)
+		if key == "" {
+			key = keyFromExpr(arg)
+		}
+		key = uniqueKey(key, keys)
+		params = append(params, m.paramCall(key, arg))
+	}
+	msg.WriteString(literals[len(literals)-1])
+
+	newArgs := []ast.Expr{
+		&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(cleanMessage(msg.String())), ValuePos: lit.ValuePos},
+	}
+	newArgs = append(newArgs, params...)
+	if cause == nil {
+		m.setCall(call, ctx, "ErrorWithContextParams", newArgs)
+	} else {
+		m.setCall(call, ctx, "WrapWithContextParams", append([]ast.Expr{cause}, newArgs...))
+	}
+	return true
+}
+
+// setCall rewrites the provided call to call the werror function with the provided name, prepending the context
+// argument to the provided arguments.
+func (m *migrator) setCall(call *ast.CallExpr, ctx, fn string, args []ast.Expr) {
+	m.changed = true
+	m.usedWerror = true
+	call.Fun = &ast.SelectorExpr{
+		X:   ast.NewIdent(m.localName(werrorImportPath)),
+		Sel: ast.NewIdent(fn),
+	}
+	call.Args = append([]ast.Expr{m.contextExpr(ctx)}, args...)
+}
+
+func (m *migrator) contextExpr(ctx string) ast.Expr {
+	if ctx != "" {
+		return ast.NewIdent(ctx)
+	}
+	m.usedContext = true
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent(m.localName(contextImportPath)),
+			Sel: ast.NewIdent("TODO"),
+		},
+	}
+}
+
+func (m *migrator) paramCall(key string, arg ast.Expr) ast.Expr {
+	fn := "UnsafeParam"
+	if _, ok := m.safeKeys[key]; ok {
+		fn = "SafeParam"
+	}
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent(m.localName(werrorImportPath)),
+			Sel: ast.NewIdent(fn),
+		},
+		Args: []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(key)},
+			arg,
+		},
+	}
+}
+
+// pkgPath returns the import path of the package referenced by the provided expression.
+func (m *migrator) pkgPath(expr ast.Expr) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	// identifiers that refer to imported packages are not resolved to objects by the parser
+	if !ok || ident.Obj != nil {
+		return "", false
+	}
+	path, ok := m.imports[ident.Name]
+	return path, ok
+}
+
+func (m *migrator) isPkgSelector(expr ast.Expr, path, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	p, ok := m.pkgPath(sel.X)
+	return ok && p == path
+}
+
+// localName returns the name used to refer to the package with the provided import path in the file being migrated.
+func (m *migrator) localName(path string) string {
+	for name, p := range m.imports {
+		if p == path {
+			return name
+		}
+	}
+	return defaultImportName(path)
+}
+
+// fixImports adds the imports required by rewritten call sites and removes the imports that are no longer used.
+func (m *migrator) fixImports() {
+	used := make(map[string]bool)
+	ast.Inspect(m.file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	for _, path := range []string{fmtImportPath, errorsImportPath, pkgErrorsImportPath} {
+		if name, ok := m.importedAs(path); ok && !used[name] {
+			m.deleteImport(path)
+		}
+	}
+	if m.usedContext {
+		m.addImport(contextImportPath)
+	}
+	if m.usedWerror {
+		m.addImport(werrorImportPath)
+	}
+}
+
+func (m *migrator) importedAs(path string) (string, bool) {
+	for name, p := range m.imports {
+		if p == path {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (m *migrator) deleteImport(path string) {
+	for i := 0; i < len(m.file.Decls); i++ {
+		decl, ok := m.file.Decls[i].(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		specs := decl.Specs[:0]
+		for _, spec := range decl.Specs {
+			if importSpecPath(spec.(*ast.ImportSpec)) != path {
+				specs = append(specs, spec)
+			}
+		}
+		decl.Specs = specs
+		if len(decl.Specs) == 0 {
+			m.file.Decls = append(m.file.Decls[:i], m.file.Decls[i+1:]...)
+			i--
+		}
+	}
+	for name, p := range m.imports {
+		if p == path {
+			delete(m.imports, name)
+		}
+	}
+}
+
+func (m *migrator) addImport(path string) {
+	if _, ok := m.importedAs(path); ok {
+		return
+	}
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	if defaultImportName(path) != importPathBase(path) {
+		spec.Name = ast.NewIdent(defaultImportName(path))
+	}
+	m.imports[defaultImportName(path)] = path
+	for _, decl := range m.file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		if !decl.Lparen.IsValid() {
+			decl.Lparen = decl.Specs[0].Pos()
+			decl.Rparen = decl.End()
+		}
+		spec.Path.ValuePos = decl.Specs[len(decl.Specs)-1].End()
+		decl.Specs = append(decl.Specs, spec)
+		return
+	}
+	m.file.Decls = append([]ast.Decl{&ast.GenDecl{
+		Tok:   token.IMPORT,
+		Specs: []ast.Spec{spec},
+	}}, m.file.Decls...)
+}
+
+// groupImports formats the provided source, rewriting its import block so that standard library imports and all other
+// imports are sorted in two separate groups. The import block is left as-is if any of its imports have comments.
+func groupImports(src []byte) ([]byte, error) {
+	src, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Decls) != 1 {
+		return src, nil
+	}
+	decl, ok := file.Decls[0].(*ast.GenDecl)
+	if !ok || decl.Tok != token.IMPORT || !decl.Lparen.IsValid() {
+		return src, nil
+	}
+	var std, other []string
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ImportSpec)
+		if spec.Doc != nil || spec.Comment != nil {
+			return src, nil
+		}
+		line := spec.Path.Value
+		if spec.Name != nil {
+			line = spec.Name.Name + " " + line
+		}
+		if path := importSpecPath(spec); strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, line)
+		} else {
+			std = append(std, line)
+		}
+	}
+	sort.Slice(std, func(i, j int) bool { return importLinePath(std[i]) < importLinePath(std[j]) })
+	sort.Slice(other, func(i, j int) bool { return importLinePath(other[i]) < importLinePath(other[j]) })
+
+	var block bytes.Buffer
+	block.WriteString("(\n")
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 && len(other) > 0 {
+			block.WriteString("\n")
+		}
+		for _, line := range group {
+			block.WriteString("\t" + line + "\n")
+		}
+	}
+	block.WriteString(")")
+
+	start := fset.Position(decl.Lparen).Offset
+	end := fset.Position(decl.Rparen).Offset + 1
+	var out bytes.Buffer
+	out.Write(src[:start])
+	out.Write(block.Bytes())
+	out.Write(src[end:])
+	return format.Source(out.Bytes())
+}
+
+func importLinePath(line string) string {
+	return line[strings.IndexByte(line, '"'):]
+}
+
+func importSpecPath(spec *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path
+}
+
+func defaultImportName(path string) string {
+	if path == werrorImportPath {
+		return "werror"
+	}
+	return importPathBase(path)
+}
+
+func importPathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// parseFormat splits the provided format string into the literal text surrounding each verb and the verbs
+// themselves, so that len(literals) == len(verbs)+1. Returns false if the format string uses explicit argument indexes
+// or '*' widths, which are not supported.
+func parseFormat(format string) (literals []string, verbs []rune, ok bool) {
+	var current strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			current.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			current.WriteByte('%')
+			continue
+		}
+		// skip flags, width and precision
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) || format[i] == '[' || format[i] == '*' {
+			return nil, nil, false
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+		literals = append(literals, current.String())
+		verbs = append(verbs, verb)
+		current.Reset()
+	}
+	return append(literals, current.String()), verbs, true
+}
+
+// keyPrefixRegexp matches text of the form "key=" that immediately precedes a verb.
+var keyPrefixRegexp = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)=$`)
+
+var emptyPairReplacer = strings.NewReplacer(`''`, "", `""`, "", "``", "", "()", "", "[]", "", "{}", "", "<>", "")
+
+// cleanMessage tidies up a message from which verbs have been removed.
+func cleanMessage(msg string) string {
+	msg = strings.Join(strings.Fields(emptyPairReplacer.Replace(msg)), " ")
+	msg = strings.NewReplacer(" :", ":", " ,", ",", " ;", ";").Replace(msg)
+	return strings.Trim(msg, " :;,-")
+}
+
+func keyFromExpr(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return lowerCamel(e.Name)
+	case *ast.SelectorExpr:
+		return lowerCamel(e.Sel.Name)
+	case *ast.CallExpr:
+		return keyFromExpr(e.Fun)
+	case *ast.StarExpr:
+		return keyFromExpr(e.X)
+	case *ast.UnaryExpr:
+		return keyFromExpr(e.X)
+	case *ast.ParenExpr:
+		return keyFromExpr(e.X)
+	case *ast.IndexExpr:
+		return keyFromExpr(e.X)
+	}
+	return "param"
+}
+
+func uniqueKey(key string, keys map[string]struct{}) string {
+	unique := key
+	for i := 2; ; i++ {
+		if _, exists := keys[unique]; !exists {
+			break
+		}
+		unique = key + strconv.Itoa(i)
+	}
+	keys[unique] = struct{}{}
+	return unique
+}
+
+// lowerCamel converts an identifier to lower camel case, lowering a leading initialism as a whole. For example,
+// "UserID" becomes "userID" and "URLPath" becomes "urlPath".
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	switch {
+	case upper == len(runes):
+		upper = len(runes)
+	case upper > 1:
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// isErrorLike returns true if the name of the provided expression indicates that it is an error.
+func isErrorLike(expr ast.Expr) bool {
+	var name string
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	default:
+		return false
+	}
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, "err") || strings.HasSuffix(name, "error")
+}
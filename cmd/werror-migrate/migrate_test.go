@@ -0,0 +1,261 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	for _, currCase := range []struct {
+		name     string
+		cfg      Config
+		src      string
+		want     string
+		// todoCtxs are the positions of the call sites that use context.TODO()
+		todoCtxs []string
+	}{
+		{
+			name: "fmt.Errorf with wrap verb and in-scope context",
+			cfg:  Config{SafeKeys: []string{"userID"}},
+			src: `package foo
+
+import (
+	"context"
+	"fmt"
+)
+
+func get(ctx context.Context, userID string, path string) error {
+	if err := load(path); err != nil {
+		return fmt.Errorf("failed to load user %s from %q: %w", userID, path, err)
+	}
+	return nil
+}
+`,
+			want: `package foo
+
+import (
+	"context"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+func get(ctx context.Context, userID string, path string) error {
+	if err := load(path); err != nil {
+		return werror.WrapWithContextParams(ctx, err, "failed to load user from", werror.SafeParam("userID", userID), werror.UnsafeParam("path", path))
+	}
+	return nil
+}
+`,
+		},
+		{
+			name: "fmt.Errorf without context uses TODO and treats trailing err as cause",
+			src: `package foo
+
+import "fmt"
+
+func get(req Request) error {
+	return fmt.Errorf("request failed for id=%d: %v", req.ID, err)
+}
+`,
+			want: `package foo
+
+import (
+	"context"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+func get(req Request) error {
+	return werror.WrapWithContextParams(context.TODO(), err, "request failed for", werror.UnsafeParam("id", req.ID))
+}
+`,
+			todoCtxs: []string{"foo.go:6:9"},
+		},
+		{
+			name: "errors.New and context in enclosing function literal",
+			src: `package foo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+func run(ctx context.Context) {
+	fmt.Println("running")
+	_ = func() error {
+		return errors.New("closure failed")
+	}
+}
+`,
+			want: `package foo
+
+import (
+	"context"
+	"fmt"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+func run(ctx context.Context) {
+	fmt.Println("running")
+	_ = func() error {
+		return werror.ErrorWithContextParams(ctx, "closure failed")
+	}
+}
+`,
+		},
+		{
+			name: "pkg/errors",
+			src: `package foo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+func run(reqCtx context.Context, name string) error {
+	if err := do(); err != nil {
+		return errors.Wrapf(err, "failed to run %s", name)
+	}
+	if err := do(); err != nil {
+		return errors.Wrap(err, "failed again")
+	}
+	return errors.Errorf("count=%d", 3)
+}
+`,
+			want: `package foo
+
+import (
+	"context"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+func run(reqCtx context.Context, name string) error {
+	if err := do(); err != nil {
+		return werror.WrapWithContextParams(reqCtx, err, "failed to run", werror.UnsafeParam("name", name))
+	}
+	if err := do(); err != nil {
+		return werror.WrapWithContextParams(reqCtx, err, "failed again")
+	}
+	return werror.ErrorWithContextParams(reqCtx, "", werror.UnsafeParam("count", 3))
+}
+`,
+		},
+		{
+			name: "deprecated werror functions",
+			cfg:  Config{MigrateDeprecated: true},
+			src: `package foo
+
+import (
+	"context"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+func run(ctx context.Context, err error) error {
+	if err != nil {
+		return werror.Wrap(err, "failed", werror.SafeParam("key", "value"))
+	}
+	return werror.Error("empty")
+}
+`,
+			want: `package foo
+
+import (
+	"context"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+func run(ctx context.Context, err error) error {
+	if err != nil {
+		return werror.WrapWithContextParams(ctx, err, "failed", werror.SafeParam("key", "value"))
+	}
+	return werror.ErrorWithContextParams(ctx, "empty")
+}
+`,
+		},
+		{
+			name: "package-level errors are left unchanged",
+			src: `package foo
+
+import "errors"
+
+var ErrNotFound = errors.New("not found")
+
+var newErr = func() error {
+	return errors.New("in function literal")
+}
+`,
+			want: `package foo
+
+import (
+	"context"
+	"errors"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+var ErrNotFound = errors.New("not found")
+
+var newErr = func() error {
+	return werror.ErrorWithContextParams(context.TODO(), "in function literal")
+}
+`,
+			todoCtxs: []string{"foo.go:8:9"},
+		},
+		{
+			name: "unsupported calls are left unchanged",
+			src: `package foo
+
+import "fmt"
+
+func run(format string, args []interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf(format)
+	}
+	return fmt.Errorf("%[1]s %[1]s", args...)
+}
+`,
+			want: `package foo
+
+import "fmt"
+
+func run(format string, args []interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf(format)
+	}
+	return fmt.Errorf("%[1]s %[1]s", args...)
+}
+`,
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			res, err := Migrate("foo.go", []byte(currCase.src), currCase.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, currCase.want, string(res.Source))
+			var todoCtxs []string
+			for _, pos := range res.TODOContexts {
+				todoCtxs = append(todoCtxs, pos.String())
+			}
+			assert.Equal(t, currCase.todoCtxs, todoCtxs)
+		})
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	for in, want := range map[string]string{
+		"userID":  "userID",
+		"UserID":  "userID",
+		"ID":      "id",
+		"URLPath": "urlPath",
+		"path":    "path",
+	} {
+		assert.Equal(t, want, lowerCamel(in), in)
+	}
+}
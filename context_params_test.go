@@ -0,0 +1,98 @@
+package werror_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextParamsNotStoredAgain(t *testing.T) {
+	var levels []werror.CreatedError
+	unregister := werror.RegisterObserver(func(e werror.CreatedError) {
+		levels = append(levels, e)
+	})
+	defer unregister()
+
+	ctx := wparams.ContextWithSafeAndUnsafeParams(context.Background(),
+		map[string]interface{}{"requestId": "r1", "tags": []string{"a"}},
+		map[string]interface{}{"userAgent": "curl"},
+	)
+	inner := werror.ErrorWithContextParams(ctx, "inner")
+	middle := werror.WrapWithContextParams(ctx, inner, "middle")
+	// a context with a different value for a key is stored again
+	outerCtx := wparams.ContextWithSafeParam(ctx, "requestId", "r2")
+	outer := werror.WrapWithContextParams(outerCtx, middle, "outer", werror.SafeParam("tags", []string{"a"}))
+	require.Len(t, levels, 3)
+
+	assert.Equal(t, map[string]interface{}{"requestId": "r1", "tags": []string{"a"}}, levels[0].SafeParams())
+	assert.Equal(t, map[string]interface{}{"userAgent": "curl"}, levels[0].UnsafeParams())
+	assert.Empty(t, levels[1].SafeParams())
+	assert.Empty(t, levels[1].UnsafeParams())
+	// explicitly provided params are always stored
+	assert.Equal(t, map[string]interface{}{"requestId": "r2", "tags": []string{"a"}}, levels[2].SafeParams())
+	assert.Empty(t, levels[2].UnsafeParams())
+
+	safe, unsafe := werror.ParamsFromError(outer)
+	assert.Equal(t, map[string]interface{}{"requestId": "r1", "tags": []string{"a"}}, safe)
+	assert.Equal(t, map[string]interface{}{"userAgent": "curl"}, unsafe)
+}
+
+// BenchmarkWrapChainWithContextParams wraps an error ten times using the same context. The storedParams/op metric is
+// the total number of params stored across all levels of the chain.
+func BenchmarkWrapChainWithContextParams(b *testing.B) {
+	safe := make(map[string]interface{})
+	for i := 0; i < 5; i++ {
+		safe[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	ctx := wparams.ContextWithSafeParams(context.Background(), safe)
+	const depth = 10
+
+	for _, currCase := range []struct {
+		name   string
+		create func() error
+	}{
+		{
+			name: "context params",
+			create: func() error {
+				err := werror.ErrorWithContextParams(ctx, "error")
+				for level := 1; level < depth; level++ {
+					err = werror.WrapWithContextParams(ctx, err, "wrapped")
+				}
+				return err
+			},
+		},
+		{
+			// stores the same params at every level, which is what the context variants did before the params that
+			// are already present deeper in the chain were skipped
+			name: "same params at every level",
+			create: func() error {
+				err := werror.ErrorWithContextParams(context.Background(), "error", werror.SafeParams(safe))
+				for level := 1; level < depth; level++ {
+					err = werror.WrapWithContextParams(context.Background(), err, "wrapped", werror.SafeParams(safe))
+				}
+				return err
+			},
+		},
+	} {
+		b.Run(currCase.name, func(b *testing.B) {
+			stored := 0
+			unregister := werror.RegisterObserver(func(e werror.CreatedError) {
+				stored += len(e.SafeParams())
+			})
+			currCase.create()
+			unregister()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = currCase.create()
+			}
+			b.ReportMetric(float64(stored), "storedParams/op")
+		})
+	}
+}
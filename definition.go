@@ -0,0 +1,90 @@
+package werror
+
+import (
+	"context"
+)
+
+// Definition is a typed error definition whose params are declared by the struct type P. The fields of P are stored
+// as params using StructParams, so P declares which params are safe and unsafe using "werror" struct tags:
+//
+//	type UserNotFoundParams struct {
+//		UserID string `werror:"userId,safe"`
+//		Email  string `werror:"email,unsafe"`
+//	}
+//
+//	var ErrUserNotFound = werror.Define[UserNotFoundParams]("Users:UserNotFound", werror.CategoryNotFound)
+//
+//	err := ErrUserNotFound.New(ctx, UserNotFoundParams{UserID: id})
+//	if params, ok := ErrUserNotFound.Params(err); ok {
+//		...
+//	}
+type Definition[P any] struct {
+	errorType ErrorType
+}
+
+// Define returns a new Definition with the provided name and category. Panics if the name is not of the form
+// "Namespace:Name" or the category is not valid. It is intended to be used to initialize package-level variables.
+func Define[P any](name string, category Category) *Definition[P] {
+	return &Definition[P]{
+		errorType: MustErrorType(category, name),
+	}
+}
+
+// Type returns the error type of the definition.
+func (d *Definition[P]) Type() ErrorType {
+	return d.errorType
+}
+
+// New returns a new error of this definition. The message of the error is the name of the definition and the error
+// stores the fields of params, the provided params and any wparams parameters stored in the context.
+func (d *Definition[P]) New(ctx context.Context, params P, extraParams ...Param) error {
+	return newWerrorWithContext(ctx, d.errorType.Name(), nil, d.params(params, extraParams)...)
+}
+
+// Wrap is like New, but the returned error stores the provided error as its cause. Returns nil if err is nil.
+func (d *Definition[P]) Wrap(ctx context.Context, err error, params P, extraParams ...Param) error {
+	if err == nil {
+		return nil
+	}
+	return newWerrorWithContext(ctx, d.errorType.Name(), err, d.params(params, extraParams)...)
+}
+
+func (d *Definition[P]) params(params P, extraParams []Param) []Param {
+	allParams := []Param{StructParams(params), Type(d.errorType), param(func(z *werror) {
+		z.definitionParams = params
+	})}
+	return append(allParams, extraParams...)
+}
+
+// Is returns true if the provided error or any of its causes declares the type of this definition.
+func (d *Definition[P]) Is(err error) bool {
+	return d.find(err) != nil
+}
+
+// Params returns the params of the outermost error in the chain of the provided error that was created by New or
+// Wrap of a definition with the same type. Returns false if there is no such error, for example because the error
+// was reconstructed from a remote response.
+func (d *Definition[P]) Params(err error) (P, bool) {
+	if we := d.find(err); we != nil {
+		if params, ok := we.definitionParams.(P); ok {
+			return params, true
+		}
+	}
+	var zero P
+	return zero, false
+}
+
+// find returns the outermost werror in the cause chain of err that declares the type of this definition.
+func (d *Definition[P]) find(err error) *werror {
+	for currErr := err; currErr != nil; {
+		if we, ok := currErr.(*werror); ok && we.errorType == d.errorType {
+			return we
+		}
+		causer, ok := currErr.(Causer)
+		if !ok {
+			return nil
+		}
+		currErr = causer.Cause()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package werror_test
+
+import (
+	"context"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userNotFoundParams struct {
+	UserID string `werror:"userId,safe"`
+	Email  string `werror:"email,unsafe"`
+}
+
+var (
+	errUserNotFound  = werror.Define[userNotFoundParams]("Users:UserNotFound", werror.CategoryNotFound)
+	errUserSuspended = werror.Define[struct{}]("Users:UserSuspended", werror.CategoryPermissionDenied)
+)
+
+func TestDefinition(t *testing.T) {
+	ctx := context.Background()
+	params := userNotFoundParams{UserID: "user-1", Email: "user@example.com"}
+	err := errUserNotFound.New(ctx, params, werror.SafeParam("extra", "value"))
+	require.Error(t, err)
+	assert.EqualError(t, err, "Users:UserNotFound")
+
+	errorType, ok := werror.TypeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, errUserNotFound.Type(), errorType)
+	assert.Equal(t, werror.CategoryNotFound, errorType.Category())
+	assert.NotEmpty(t, werror.InstanceIDFromError(err))
+
+	safe, unsafe := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{"userId": "user-1", "extra": "value"}, safe)
+	assert.Equal(t, map[string]interface{}{"email": "user@example.com"}, unsafe)
+
+	wrapped := werror.WrapWithContextParams(ctx, err, "failed to get user")
+	assert.True(t, errUserNotFound.Is(wrapped))
+	assert.False(t, errUserSuspended.Is(wrapped))
+	gotParams, ok := errUserNotFound.Params(wrapped)
+	require.True(t, ok)
+	assert.Equal(t, params, gotParams)
+	_, ok = errUserSuspended.Params(wrapped)
+	assert.False(t, ok)
+
+	// errors with the same type that were not created by the definition match but do not have params
+	remote := werror.ErrorWithContextParams(ctx, "remote", werror.Type(errUserNotFound.Type()))
+	assert.True(t, errUserNotFound.Is(remote))
+	_, ok = errUserNotFound.Params(remote)
+	assert.False(t, ok)
+
+	suspended := errUserSuspended.Wrap(ctx, err, struct{}{})
+	assert.EqualError(t, suspended, "Users:UserSuspended: Users:UserNotFound")
+	assert.Equal(t, werror.CategoryPermissionDenied, werror.CategoryFromError(suspended))
+	assert.True(t, errUserNotFound.Is(suspended))
+	assert.Nil(t, errUserSuspended.Wrap(ctx, nil, struct{}{}))
+
+	assert.Panics(t, func() {
+		werror.Define[struct{}]("invalid", werror.CategoryInternal)
+	})
+}
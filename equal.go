@@ -0,0 +1,224 @@
+package werror
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// EqualOption configures Equal and Diff.
+type EqualOption func(*equalConfig)
+
+type equalConfig struct {
+	ignoredParams map[string]struct{}
+	comparers     map[string]func(a, b interface{}) bool
+	compareStacks bool
+}
+
+// IgnoreParams configures Equal and Diff to ignore the safe and unsafe params with the provided keys.
+func IgnoreParams(keys ...string) EqualOption {
+	return func(cfg *equalConfig) {
+		for _, k := range keys {
+			cfg.ignoredParams[k] = struct{}{}
+		}
+	}
+}
+
+// ParamComparer configures Equal and Diff to compare the values of the params with the provided key using the provided
+// function instead of reflect.DeepEqual. This is useful to compare errors after a serialization round trip, which may
+// change the types of values, for example from int to float64.
+func ParamComparer(key string, equal func(a, b interface{}) bool) EqualOption {
+	return func(cfg *equalConfig) {
+		cfg.comparers[key] = equal
+	}
+}
+
+// CompareStackFunctions configures Equal and Diff to also compare the stack traces of the errors by the names of the
+// functions of their frames. Program counters, files and line numbers are never compared.
+func CompareStackFunctions() EqualOption {
+	return func(cfg *equalConfig) {
+		cfg.compareStacks = true
+	}
+}
+
+// Equal returns true if the provided errors are structurally equal as determined by Diff.
+func Equal(a, b error, options ...EqualOption) bool {
+	return Diff(a, b, options...) == ""
+}
+
+// Diff compares the provided errors and returns a description of their differences, or the empty string if they are
+// structurally equal. The errors are compared level by level along their cause chains. For each level, the message,
+// the safe and unsafe params stored at that level, the declared type and whether the error is retryable are compared.
+// Errors in the chain that are not werrors are compared by their Error() output, and their causes are not compared.
+// Stack traces, instance IDs and the identity of the errors are not compared unless configured by the options.
+func Diff(a, b error, options ...EqualOption) string {
+	cfg := &equalConfig{
+		ignoredParams: make(map[string]struct{}),
+		comparers:     make(map[string]func(a, b interface{}) bool),
+	}
+	for _, option := range options {
+		option(cfg)
+	}
+	var diffs []string
+	for level := 0; a != nil || b != nil; level++ {
+		addDiff := func(format string, args ...interface{}) {
+			diffs = append(diffs, fmt.Sprintf("level %d: ", level)+fmt.Sprintf(format, args...))
+		}
+		if a == nil || b == nil {
+			addDiff("error %s != %s", describeLevel(a), describeLevel(b))
+			break
+		}
+		werrA, okA := a.(Werror)
+		werrB, okB := b.(Werror)
+		if !okA || !okB {
+			if okA != okB || a.Error() != b.Error() {
+				addDiff("error %s != %s", describeLevel(a), describeLevel(b))
+			}
+			break
+		}
+		if werrA.Message() != werrB.Message() {
+			addDiff("message %q != %q", werrA.Message(), werrB.Message())
+		}
+		diffLevelParams(cfg, werrA, werrB, addDiff)
+		if typeA, typeB := levelType(werrA), levelType(werrB); typeA != typeB {
+			addDiff("type %q != %q", typeA.Name(), typeB.Name())
+		}
+		if retryA, retryB := levelRetryHint(werrA), levelRetryHint(werrB); retryA != retryB {
+			addDiff("retryable %s != %s", retryA, retryB)
+		}
+		if cfg.compareStacks {
+			if stackA, stackB := stackFunctions(werrA), stackFunctions(werrB); !reflect.DeepEqual(stackA, stackB) {
+				addDiff("stack %v != %v", stackA, stackB)
+			}
+		}
+		a, b = werrA.Cause(), werrB.Cause()
+	}
+	return strings.Join(diffs, "\n")
+}
+
+func diffLevelParams(cfg *equalConfig, a, b Werror, addDiff func(format string, args ...interface{})) {
+	safeA, unsafeA := paramsAtLevel(a)
+	safeB, unsafeB := paramsAtLevel(b)
+	for _, params := range []struct {
+		safety string
+		a, b   map[string]interface{}
+	}{
+		{safety: "safe", a: safeA, b: safeB},
+		{safety: "unsafe", a: unsafeA, b: unsafeB},
+	} {
+		keys := make(map[string]struct{})
+		for k := range params.a {
+			keys[k] = struct{}{}
+		}
+		for k := range params.b {
+			keys[k] = struct{}{}
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			if _, ignored := cfg.ignoredParams[k]; !ignored {
+				sortedKeys = append(sortedKeys, k)
+			}
+		}
+		sort.Strings(sortedKeys)
+		for _, k := range sortedKeys {
+			valA, inA := params.a[k]
+			valB, inB := params.b[k]
+			switch {
+			case !inA:
+				addDiff("%s param %q missing != %#v", params.safety, k, valB)
+			case !inB:
+				addDiff("%s param %q %#v != missing", params.safety, k, valA)
+			default:
+				equal := reflect.DeepEqual
+				if comparer, ok := cfg.comparers[k]; ok {
+					equal = comparer
+				}
+				if !equal(valA, valB) {
+					addDiff("%s param %q %#v != %#v", params.safety, k, valA, valB)
+				}
+			}
+		}
+	}
+}
+
+// paramsAtLevel returns the params stored at the level of the provided error, not including the params of its
+// causes.
+func paramsAtLevel(err Werror) (safe map[string]interface{}, unsafe map[string]interface{}) {
+	if we, ok := err.(*werror); ok {
+		safe, unsafe = make(map[string]interface{}), make(map[string]interface{})
+		for _, p := range we.params {
+			if p.safe {
+				safe[p.key] = p.value
+			} else {
+				unsafe[p.key] = p.value
+			}
+		}
+		return safe, unsafe
+	}
+	// other implementations only expose the params of the entire chain
+	safe, unsafe = err.SafeParams(), err.UnsafeParams()
+	causeSafe, causeUnsafe := ParamsFromError(err.Cause())
+	for k := range causeSafe {
+		delete(safe, k)
+	}
+	for k := range causeUnsafe {
+		delete(unsafe, k)
+	}
+	return safe, unsafe
+}
+
+func levelType(err Werror) ErrorType {
+	if we, ok := err.(*werror); ok {
+		return we.errorType
+	}
+	return ErrorType{}
+}
+
+func levelRetryHint(err Werror) retryHint {
+	if we, ok := err.(*werror); ok {
+		return we.retryable
+	}
+	return retryUnset
+}
+
+func (h retryHint) String() string {
+	switch h {
+	case retryAllowed:
+		return "true"
+	case retryNotAllowed:
+		return "false"
+	default:
+		return "unset"
+	}
+}
+
+func describeLevel(err error) string {
+	switch e := err.(type) {
+	case nil:
+		return "<nil>"
+	case Werror:
+		return fmt.Sprintf("werror %q", e.Message())
+	default:
+		return fmt.Sprintf("%T %q", err, err.Error())
+	}
+}
+
+// stackFunctions returns the names of the functions of the frames of the stack trace of the provided error.
+func stackFunctions(err Werror) []string {
+	st, ok := err.StackTrace().(*stack)
+	if !ok || st == nil {
+		return nil
+	}
+	var functions []string
+	frames := runtime.CallersFrames(*st)
+	for {
+		frame, more := frames.Next()
+		functions = append(functions, frame.Function)
+		if !more {
+			break
+		}
+	}
+	return functions
+}
@@ -0,0 +1,109 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEqualTestError(userID interface{}) error {
+	ctx := context.Background()
+	return werror.WrapWithContextParams(ctx,
+		werror.WrapWithContextParams(ctx, errors.New("connection refused"), "query failed",
+			werror.SafeParam("userId", userID),
+			werror.UnsafeParam("query", "SELECT 1"),
+			werror.Retryable(true),
+		),
+		"failed to load user",
+		werror.Type(testNotFoundType),
+	)
+}
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+	for _, currCase := range []struct {
+		name     string
+		a, b     error
+		options  []werror.EqualOption
+		wantDiff string
+	}{
+		{
+			name: "equal errors with different stacks and instance IDs",
+			a:    newEqualTestError("user-1"),
+			b:    func() error { return newEqualTestError("user-1") }(),
+		},
+		{
+			name:     "different params",
+			a:        newEqualTestError("user-1"),
+			b:        newEqualTestError("user-2"),
+			wantDiff: `level 1: safe param "userId" "user-1" != "user-2"`,
+		},
+		{
+			name:    "ignored params",
+			a:       newEqualTestError("user-1"),
+			b:       newEqualTestError("user-2"),
+			options: []werror.EqualOption{werror.IgnoreParams("userId")},
+		},
+		{
+			name: "param comparer",
+			a:    newEqualTestError(42),
+			b:    newEqualTestError(float64(42)),
+			options: []werror.EqualOption{werror.ParamComparer("userId", func(a, b interface{}) bool {
+				return fmt.Sprint(a) == fmt.Sprint(b)
+			})},
+		},
+		{
+			name: "different messages, types and retryability",
+			a:    werror.ErrorWithContextParams(ctx, "a", werror.Type(testNotFoundType), werror.Retryable(false)),
+			b:    werror.ErrorWithContextParams(ctx, "b", werror.UnsafeParam("key", "value")),
+			wantDiff: "" +
+				`level 0: message "a" != "b"` + "\n" +
+				`level 0: unsafe param "key" missing != "value"` + "\n" +
+				`level 0: type "Test:UserNotFound" != ""` + "\n" +
+				`level 0: retryable false != unset`,
+		},
+		{
+			name:     "different non-werror causes",
+			a:        werror.WrapWithContextParams(ctx, errors.New("a"), "outer"),
+			b:        werror.WrapWithContextParams(ctx, errors.New("b"), "outer"),
+			wantDiff: `level 1: error *errors.errorString "a" != *errors.errorString "b"`,
+		},
+		{
+			name:     "different chain lengths",
+			a:        werror.WrapWithContextParams(ctx, werror.ErrorWithContextParams(ctx, "inner"), "outer"),
+			b:        werror.ErrorWithContextParams(ctx, "outer"),
+			wantDiff: `level 1: error werror "inner" != <nil>`,
+		},
+		{
+			name:     "werror and non-werror",
+			a:        werror.ErrorWithContextParams(ctx, "error"),
+			b:        errors.New("error"),
+			wantDiff: `level 0: error werror "error" != *errors.errorString "error"`,
+		},
+		{
+			name:    "same stack functions",
+			a:       newEqualTestError("user-1"),
+			b:       newEqualTestError("user-1"),
+			options: []werror.EqualOption{werror.CompareStackFunctions()},
+		},
+		{
+			name: "nil errors",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			assert.Equal(t, currCase.wantDiff, werror.Diff(currCase.a, currCase.b, currCase.options...))
+			assert.Equal(t, currCase.wantDiff == "", werror.Equal(currCase.a, currCase.b, currCase.options...))
+		})
+	}
+}
+
+func TestDiffStackFunctions(t *testing.T) {
+	a := werror.ErrorWithContextParams(context.Background(), "error")
+	b := func() error { return werror.ErrorWithContextParams(context.Background(), "error") }()
+	assert.True(t, werror.Equal(a, b))
+	assert.False(t, werror.Equal(a, b, werror.CompareStackFunctions()))
+}
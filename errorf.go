@@ -0,0 +1,103 @@
+package werror
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Errorf returns a new error whose message is the provided template. The template may reference the parameters of the
+// error using placeholders of the form "{key}":
+//
+//	werror.Errorf(ctx, "failed to load user {userId} from {path}",
+//		werror.SafeParam("userId", id),
+//		werror.UnsafeParam("path", path))
+//
+// The template is used as-is as the Message() of the error so that all occurrences of the error can be grouped by their
+// message. Use RenderedMessage to get a human-readable message in which the placeholders are replaced with parameter
+// values. Like ErrorWithContextParams, the returned error also includes any wparams parameters stored in the context.
+func Errorf(ctx context.Context, template string, params ...Param) error {
+	return newWerrorWithContext(ctx, template, nil, params...)
+}
+
+// Wrapf is like Errorf, but the returned error stores the provided error as its cause. Returns nil if err is nil.
+func Wrapf(ctx context.Context, err error, template string, params ...Param) error {
+	if err == nil {
+		return nil
+	}
+	return newWerrorWithContext(ctx, template, err, params...)
+}
+
+// RenderedMessage returns the message of the provided error and its causes, in the same format as Error(), with the
+// placeholders of the form "{key}" in each werror message replaced with the value of the corresponding safe parameter.
+// Placeholders for unsafe parameters and for keys that do not exist are left as-is.
+func RenderedMessage(err error) string {
+	return renderMessage(err, false)
+}
+
+// RenderedMessageWithUnsafeParams is like RenderedMessage, but also replaces placeholders for unsafe parameters. The
+// returned message may contain unsafe data and should only be used in contexts where unsafe parameters are allowed.
+func RenderedMessageWithUnsafeParams(err error) string {
+	return renderMessage(err, true)
+}
+
+var placeholderRegexp = regexp.MustCompile(`\{([A-Za-z0-9_.\-]+)\}`)
+
+func renderMessage(err error, includeUnsafe bool) string {
+	if err == nil {
+		return ""
+	}
+	werr, ok := err.(Werror)
+	if !ok {
+		return err.Error()
+	}
+	msg := renderTemplate(werr, includeUnsafe)
+	if werr.Cause() == nil {
+		return msg
+	}
+	cause := renderMessage(werr.Cause(), includeUnsafe)
+	if msg == "" {
+		return cause
+	}
+	return msg + ": " + cause
+}
+
+func renderTemplate(err Werror, includeUnsafe bool) string {
+	msg := err.Message()
+	if !strings.Contains(msg, "{") {
+		return msg
+	}
+	var safe, unsafe map[string]interface{}
+	return placeholderRegexp.ReplaceAllStringFunc(msg, func(placeholder string) string {
+		key := placeholder[1 : len(placeholder)-1]
+		if safe == nil {
+			safe, unsafe = levelParams(err)
+		}
+		if v, ok := safe[key]; ok {
+			return fmt.Sprint(v)
+		}
+		if v, ok := unsafe[key]; ok && includeUnsafe {
+			return fmt.Sprint(v)
+		}
+		return placeholder
+	})
+}
+
+// levelParams returns the params of the provided error. The params stored on the error itself take precedence over
+// the params of its causes so that placeholders are rendered using the values provided to the same constructor.
+func levelParams(err Werror) (safe map[string]interface{}, unsafe map[string]interface{}) {
+	safe, unsafe = err.SafeParams(), err.UnsafeParams()
+	if we, ok := err.(*werror); ok {
+		for _, p := range we.params {
+			if p.safe {
+				safe[p.key] = p.value
+				delete(unsafe, p.key)
+			} else {
+				unsafe[p.key] = p.value
+				delete(safe, p.key)
+			}
+		}
+	}
+	return safe, unsafe
+}
@@ -0,0 +1,47 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorf(t *testing.T) {
+	ctx := wparams.ContextWithSafeParam(context.Background(), "requestId", "abc")
+	err := werror.Errorf(ctx, "failed to load user {userId} from {path}",
+		werror.SafeParam("userId", 123),
+		werror.UnsafeParam("path", "/secret/users.json"),
+	)
+	require.Error(t, err)
+	assert.Equal(t, "failed to load user {userId} from {path}", err.(werror.Werror).Message())
+	assert.EqualError(t, err, "failed to load user {userId} from {path}")
+	safe, unsafe := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{"requestId": "abc", "userId": 123}, safe)
+	assert.Equal(t, map[string]interface{}{"path": "/secret/users.json"}, unsafe)
+
+	assert.Equal(t, "failed to load user 123 from {path}", werror.RenderedMessage(err))
+	assert.Equal(t, "failed to load user 123 from /secret/users.json", werror.RenderedMessageWithUnsafeParams(err))
+}
+
+func TestWrapf(t *testing.T) {
+	inner := werror.Errorf(context.Background(), "user {userId} not found", werror.SafeParam("userId", "inner"))
+	outer := werror.Wrapf(context.Background(), werror.WithParams(inner), "request {requestId} for user {userId} failed",
+		werror.SafeParam("requestId", "abc"),
+		werror.SafeParam("userId", "outer"),
+	)
+	require.Error(t, outer)
+	assert.EqualError(t, outer, "request {requestId} for user {userId} failed: user {userId} not found")
+	// each level renders its placeholders using its own params
+	assert.Equal(t, "request abc for user outer failed: user inner not found", werror.RenderedMessage(outer))
+
+	plain := werror.Wrapf(context.Background(), errors.New("plain {notAPlaceholder}"), "wrapped {missing}")
+	assert.Equal(t, "wrapped {missing}: plain {notAPlaceholder}", werror.RenderedMessage(plain))
+
+	assert.Nil(t, werror.Wrapf(context.Background(), nil, "nil"))
+	assert.Equal(t, "", werror.RenderedMessage(nil))
+}
@@ -0,0 +1,198 @@
+package werror
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Category is the broad category of an error. The categories mirror the error codes defined by the Conjure wire
+// specification, and each category maps to an HTTP status code.
+type Category string
+
+const (
+	CategoryPermissionDenied      Category = "PERMISSION_DENIED"
+	CategoryInvalidArgument       Category = "INVALID_ARGUMENT"
+	CategoryNotFound              Category = "NOT_FOUND"
+	CategoryConflict              Category = "CONFLICT"
+	CategoryRequestEntityTooLarge Category = "REQUEST_ENTITY_TOO_LARGE"
+	CategoryFailedPrecondition    Category = "FAILED_PRECONDITION"
+	CategoryInternal              Category = "INTERNAL"
+	CategoryTimeout               Category = "TIMEOUT"
+	CategoryCustomClient          Category = "CUSTOM_CLIENT"
+	CategoryCustomServer          Category = "CUSTOM_SERVER"
+)
+
+var categoryStatusCodes = map[Category]int{
+	CategoryPermissionDenied:      http.StatusForbidden,
+	CategoryInvalidArgument:       http.StatusBadRequest,
+	CategoryNotFound:              http.StatusNotFound,
+	CategoryConflict:              http.StatusConflict,
+	CategoryRequestEntityTooLarge: http.StatusRequestEntityTooLarge,
+	CategoryFailedPrecondition:    http.StatusInternalServerError,
+	CategoryInternal:              http.StatusInternalServerError,
+	CategoryTimeout:               http.StatusInternalServerError,
+	CategoryCustomClient:          http.StatusBadRequest,
+	CategoryCustomServer:          http.StatusInternalServerError,
+}
+
+// StatusCode returns the HTTP status code for the category. Returns 500 for unknown categories.
+func (c Category) StatusCode() int {
+	if code, ok := categoryStatusCodes[c]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// IsValid returns true if the category is one of the categories defined by this package.
+func (c Category) IsValid() bool {
+	_, ok := categoryStatusCodes[c]
+	return ok
+}
+
+// ErrorType identifies a specific kind of error. It consists of a category and a name of the form "Namespace:Name",
+// where both the namespace and the name are UpperCamelCase.
+type ErrorType struct {
+	category Category
+	name     string
+}
+
+// Default error types for each of the non-custom categories. Errors that do not declare a type are treated as
+// DefaultInternal.
+var (
+	DefaultPermissionDenied      = ErrorType{category: CategoryPermissionDenied, name: "Default:PermissionDenied"}
+	DefaultInvalidArgument       = ErrorType{category: CategoryInvalidArgument, name: "Default:InvalidArgument"}
+	DefaultNotFound              = ErrorType{category: CategoryNotFound, name: "Default:NotFound"}
+	DefaultConflict              = ErrorType{category: CategoryConflict, name: "Default:Conflict"}
+	DefaultRequestEntityTooLarge = ErrorType{category: CategoryRequestEntityTooLarge, name: "Default:RequestEntityTooLarge"}
+	DefaultFailedPrecondition    = ErrorType{category: CategoryFailedPrecondition, name: "Default:FailedPrecondition"}
+	DefaultInternal              = ErrorType{category: CategoryInternal, name: "Default:Internal"}
+	DefaultTimeout               = ErrorType{category: CategoryTimeout, name: "Default:Timeout"}
+)
+
+var errorNameRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*:[A-Z][A-Za-z0-9]*$`)
+
+// NewErrorType returns a new ErrorType with the provided category and name. Returns an error if the category is not
+// valid or if the name is not of the form "Namespace:Name".
+func NewErrorType(category Category, name string) (ErrorType, error) {
+	if !category.IsValid() {
+		return ErrorType{}, newWerror("invalid error category", nil, SafeParam("category", string(category)))
+	}
+	if !errorNameRegexp.MatchString(name) {
+		return ErrorType{}, newWerror("error name must be of the form Namespace:Name with UpperCamelCase components", nil, SafeParam("name", name))
+	}
+	return ErrorType{category: category, name: name}, nil
+}
+
+// MustErrorType is like NewErrorType but panics if the category or name is invalid. It is intended to be used to
+// initialize package-level variables.
+func MustErrorType(category Category, name string) ErrorType {
+	errorType, err := NewErrorType(category, name)
+	if err != nil {
+		panic(err)
+	}
+	return errorType
+}
+
+// Category returns the category of the error type.
+func (t ErrorType) Category() Category {
+	return t.category
+}
+
+// Name returns the name of the error type, which is of the form "Namespace:Name".
+func (t ErrorType) Name() string {
+	return t.name
+}
+
+// String returns the name of the error type.
+func (t ErrorType) String() string {
+	return t.name
+}
+
+// IsZero returns true if t is the zero value, which is used to indicate that no error type was declared.
+func (t ErrorType) IsZero() bool {
+	return t == ErrorType{}
+}
+
+// Type returns a Param that declares the type of the error and assigns the error a newly generated instance ID.
+// The instance ID uniquely identifies this occurrence of the error and is typically returned to clients so that it
+// can be correlated with server logs.
+func Type(errorType ErrorType) Param {
+	return param(func(z *werror) {
+		z.errorType = errorType
+		if z.instanceID == "" {
+			z.instanceID = newInstanceID()
+		}
+	})
+}
+
+// InstanceID returns a Param that sets the instance ID of the error. It is typically used to preserve the instance ID
+// of an error received from a remote service.
+func InstanceID(instanceID string) Param {
+	return param(func(z *werror) {
+		z.instanceID = instanceID
+	})
+}
+
+// TypeFromError returns the type declared by the provided error or any of its causes. If multiple errors in the
+// chain declare a type, the outermost declaration wins so that callers can reclassify the errors they wrap. Returns
+// false if no error in the chain declares a type.
+func TypeFromError(err error) (ErrorType, bool) {
+	if we := typedWerror(err); we != nil {
+		return we.errorType, true
+	}
+	return ErrorType{}, false
+}
+
+// CategoryFromError returns the category of the type declared by the provided error or any of its causes, or
+// CategoryInternal if no error in the chain declares a type.
+func CategoryFromError(err error) Category {
+	if errorType, ok := TypeFromError(err); ok {
+		return errorType.Category()
+	}
+	return CategoryInternal
+}
+
+// InstanceIDFromError returns the instance ID of the error returned by TypeFromError, or the outermost instance ID in
+// the chain if no error declares a type. Returns the empty string if no error in the chain has an instance ID.
+func InstanceIDFromError(err error) string {
+	if we := typedWerror(err); we != nil {
+		return we.instanceID
+	}
+	for currErr := err; currErr != nil; {
+		if we, ok := currErr.(*werror); ok && we.instanceID != "" {
+			return we.instanceID
+		}
+		causer, ok := currErr.(Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
+	}
+	return ""
+}
+
+// typedWerror returns the outermost werror in the cause chain of err that declares a type.
+func typedWerror(err error) *werror {
+	for currErr := err; currErr != nil; {
+		if we, ok := currErr.(*werror); ok && !we.errorType.IsZero() {
+			return we
+		}
+		causer, ok := currErr.(Causer)
+		if !ok {
+			return nil
+		}
+		currErr = causer.Cause()
+	}
+	return nil
+}
+
+// newInstanceID returns a new random (version 4) UUID string.
+func newInstanceID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
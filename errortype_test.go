@@ -0,0 +1,117 @@
+package werror_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNotFoundType = werror.MustErrorType(werror.CategoryNotFound, "Test:UserNotFound")
+
+func TestNewErrorType(t *testing.T) {
+	for _, currCase := range []struct {
+		name      string
+		category  werror.Category
+		errorName string
+		wantErr   string
+	}{
+		{
+			name:      "valid",
+			category:  werror.CategoryConflict,
+			errorName: "Users:UserAlreadyExists",
+		},
+		{
+			name:      "invalid category",
+			category:  werror.Category("BAD"),
+			errorName: "Users:UserAlreadyExists",
+			wantErr:   "invalid error category",
+		},
+		{
+			name:      "name without namespace",
+			category:  werror.CategoryConflict,
+			errorName: "UserAlreadyExists",
+			wantErr:   "error name must be of the form Namespace:Name with UpperCamelCase components",
+		},
+		{
+			name:      "lower case name",
+			category:  werror.CategoryConflict,
+			errorName: "users:userAlreadyExists",
+			wantErr:   "error name must be of the form Namespace:Name with UpperCamelCase components",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			errorType, err := werror.NewErrorType(currCase.category, currCase.errorName)
+			if currCase.wantErr != "" {
+				assert.EqualError(t, err, currCase.wantErr)
+				assert.True(t, errorType.IsZero())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currCase.category, errorType.Category())
+			assert.Equal(t, currCase.errorName, errorType.Name())
+		})
+	}
+}
+
+func TestCategory_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, werror.CategoryNotFound.StatusCode())
+	assert.Equal(t, http.StatusForbidden, werror.CategoryPermissionDenied.StatusCode())
+	assert.Equal(t, http.StatusInternalServerError, werror.CategoryFailedPrecondition.StatusCode())
+	assert.Equal(t, http.StatusInternalServerError, werror.Category("UNKNOWN").StatusCode())
+}
+
+func TestTypeFromError(t *testing.T) {
+	typed := werror.ErrorWithContextParams(context.Background(), "user not found", werror.Type(testNotFoundType))
+	instanceID := werror.InstanceIDFromError(typed)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, instanceID)
+
+	for _, currCase := range []struct {
+		name           string
+		err            error
+		wantType       werror.ErrorType
+		wantOK         bool
+		wantInstanceID string
+	}{
+		{
+			name: "untyped error",
+			err:  werror.ErrorWithContextParams(context.Background(), "untyped"),
+		},
+		{
+			name: "non-werror error",
+			err:  fmt.Errorf("plain"),
+		},
+		{
+			name:           "typed error",
+			err:            typed,
+			wantType:       testNotFoundType,
+			wantOK:         true,
+			wantInstanceID: instanceID,
+		},
+		{
+			name:           "wrapped typed error",
+			err:            werror.WrapWithContextParams(context.Background(), typed, "wrapper"),
+			wantType:       testNotFoundType,
+			wantOK:         true,
+			wantInstanceID: instanceID,
+		},
+		{
+			name:           "outermost type wins",
+			err:            werror.WrapWithContextParams(context.Background(), typed, "wrapper", werror.Type(werror.DefaultInternal), werror.InstanceID("outer")),
+			wantType:       werror.DefaultInternal,
+			wantOK:         true,
+			wantInstanceID: "outer",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			errorType, ok := werror.TypeFromError(currCase.err)
+			assert.Equal(t, currCase.wantOK, ok)
+			assert.Equal(t, currCase.wantType, errorType)
+			assert.Equal(t, currCase.wantInstanceID, werror.InstanceIDFromError(currCase.err))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package werror
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxCommandOutputTail is the maximum number of trailing bytes of the standard output and standard error of a command
+// that are stored in the errors returned by RunCommand.
+const maxCommandOutputTail = 4 * 1024
+
+// RunCommand starts the provided command and waits for it to complete. The command is killed if the context is done
+// before the command completes. Returns nil if the command runs successfully.
+//
+// The returned error distinguishes between a command that could not be started, a command that was killed because the
+// context was done and a command that exited with a non-zero status. It includes any wparams parameters stored in the
+// context and stores the binary name, exit code, terminating signal and duration as safe parameters, and the full
+// arguments and the final bytes written to standard output and standard error as unsafe parameters. If cmd.Stdout and
+// cmd.Stderr are the same writer, the final bytes of the combined output are stored as the "output" parameter instead.
+//
+// The output of the command is still written to cmd.Stdout and cmd.Stderr if they are set. RunCommand replaces
+// cmd.Stdout and cmd.Stderr, unless it returns before starting the command because the context is already done.
+func RunCommand(ctx context.Context, cmd *exec.Cmd) error {
+	params := []Param{
+		SafeParam("binary", filepath.Base(cmd.Path)),
+		UnsafeParam("args", cmd.Args),
+	}
+	if err := ctx.Err(); err != nil {
+		return WrapWithContextParams(ctx, err, "failed to start command", params...)
+	}
+
+	outputParams := captureOutput(cmd)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return WrapWithContextParams(ctx, err, "failed to start command", params...)
+	}
+
+	// killed records whether the command was killed before Wait returned: a command that exits on its own around the
+	// time the context is done must not be reported as killed.
+	var (
+		mu     sync.Mutex
+		waited bool
+		killed bool
+	)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			defer mu.Unlock()
+			if !waited {
+				killed = cmd.Process.Kill() == nil
+			}
+		case <-done:
+		}
+	}()
+	err := cmd.Wait()
+	mu.Lock()
+	waited = true
+	wasKilled := killed
+	mu.Unlock()
+	close(done)
+	if err == nil {
+		return nil
+	}
+
+	params = append(params, SafeParam("duration", time.Since(start)))
+	params = append(params, outputParams()...)
+	if state := cmd.ProcessState; state != nil {
+		params = append(params, SafeParam("exitCode", state.ExitCode()))
+		if status, ok := state.Sys().(interface {
+			Exited() bool
+			Signaled() bool
+			Signal() syscall.Signal
+		}); ok {
+			if status.Signaled() {
+				params = append(params, SafeParam("signal", status.Signal().String()))
+			}
+			// the process may have exited before the signal sent by Kill was delivered
+			wasKilled = wasKilled && !status.Exited()
+		}
+	}
+	switch {
+	case wasKilled:
+		params = append(params, SafeParam("contextError", ctx.Err().Error()))
+		return WrapWithContextParams(ctx, err, "command was killed because its context was done", params...)
+	case isExitError(err):
+		return WrapWithContextParams(ctx, err, "command exited with non-zero status", params...)
+	default:
+		return WrapWithContextParams(ctx, err, "command failed", params...)
+	}
+}
+
+func isExitError(err error) bool {
+	_, ok := err.(*exec.ExitError)
+	return ok
+}
+
+// captureOutput tees the standard output and standard error of cmd into tail buffers and returns a function that returns
+// the params for the captured output. If cmd writes both streams to the same writer, os/exec writes them from a single
+// goroutine, so the streams are captured together as the "output" param to preserve that guarantee.
+func captureOutput(cmd *exec.Cmd) func() []Param {
+	if cmd.Stdout != nil && sameWriter(cmd.Stdout, cmd.Stderr) {
+		output := &tailBuffer{limit: maxCommandOutputTail}
+		tee := teeWriter(cmd.Stdout, output)
+		cmd.Stdout = tee
+		cmd.Stderr = tee
+		return func() []Param {
+			return []Param{UnsafeParam("output", output.String())}
+		}
+	}
+	stdout := &tailBuffer{limit: maxCommandOutputTail}
+	stderr := &tailBuffer{limit: maxCommandOutputTail}
+	cmd.Stdout = teeWriter(cmd.Stdout, stdout)
+	cmd.Stderr = teeWriter(cmd.Stderr, stderr)
+	return func() []Param {
+		return []Param{
+			UnsafeParam("stdout", stdout.String()),
+			UnsafeParam("stderr", stderr.String()),
+		}
+	}
+}
+
+// sameWriter returns true if a and b are equal, using the same comparison as os/exec. Writers whose dynamic type is not
+// comparable are never considered equal.
+func sameWriter(a, b io.Writer) bool {
+	if a == nil || b == nil || !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+func teeWriter(w io.Writer, tail *tailBuffer) io.Writer {
+	if w == nil {
+		return tail
+	}
+	return io.MultiWriter(w, tail)
+}
+
+// tailBuffer is an io.Writer that retains the last limit bytes written to it. It is safe for concurrent use.
+type tailBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	if len(p) > b.limit {
+		p = p[len(p)-b.limit:]
+	}
+	if overflow := len(b.buf) + len(p) - b.limit; overflow > 0 {
+		b.buf = append(b.buf[:0], b.buf[overflow:]...)
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
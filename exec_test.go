@@ -0,0 +1,129 @@
+package werror_test
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommand(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var stdout bytes.Buffer
+		cmd := exec.Command("sh", "-c", "echo hello")
+		cmd.Stdout = &stdout
+		require.NoError(t, werror.RunCommand(context.Background(), cmd))
+		assert.Equal(t, "hello\n", stdout.String())
+	})
+
+	t.Run("non-zero exit", func(t *testing.T) {
+		var stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", "echo out; echo err >&2; exit 3")
+		cmd.Stderr = &stderr
+		err := werror.RunCommand(context.Background(), cmd)
+		require.Error(t, err)
+		assert.Equal(t, "err\n", stderr.String(), "output is still written to the caller's writer")
+
+		werr, ok := err.(werror.Werror)
+		require.True(t, ok)
+		assert.Equal(t, "command exited with non-zero status", werr.Message())
+		var exitErr *exec.ExitError
+		assert.ErrorAs(t, err, &exitErr)
+
+		safe, unsafe := werror.ParamsFromError(err)
+		assert.Equal(t, "sh", safe["binary"])
+		assert.Equal(t, 3, safe["exitCode"])
+		assert.NotContains(t, safe, "signal")
+		assert.IsType(t, time.Duration(0), safe["duration"])
+		assert.Equal(t, []string{"sh", "-c", "echo out; echo err >&2; exit 3"}, unsafe["args"])
+		assert.Equal(t, "out\n", unsafe["stdout"])
+		assert.Equal(t, "err\n", unsafe["stderr"])
+	})
+
+	t.Run("combined output", func(t *testing.T) {
+		// a writer shared by both streams must only be written from one goroutine at a time
+		var output bytes.Buffer
+		cmd := exec.Command("sh", "-c", "for i in 1 2 3 4 5; do echo out$i; echo err$i >&2; done; exit 3")
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		err := werror.RunCommand(context.Background(), cmd)
+		require.Error(t, err)
+		assert.Contains(t, output.String(), "out5\n")
+		assert.Contains(t, output.String(), "err5\n")
+
+		_, unsafe := werror.ParamsFromError(err)
+		assert.Equal(t, output.String(), unsafe["output"])
+		assert.NotContains(t, unsafe, "stdout")
+		assert.NotContains(t, unsafe, "stderr")
+	})
+
+	t.Run("output tail is bounded", func(t *testing.T) {
+		err := werror.RunCommand(context.Background(), exec.Command("sh", "-c", "head -c 10000 /dev/zero | tr '\\0' a; echo -n end; exit 1"))
+		require.Error(t, err)
+		stdout, _ := werror.ParamFromError(err, "stdout")
+		require.IsType(t, "", stdout)
+		assert.Len(t, stdout, 4096)
+		assert.True(t, strings.HasSuffix(stdout.(string), "aaaend"))
+	})
+
+	t.Run("start failure", func(t *testing.T) {
+		err := werror.RunCommand(context.Background(), exec.Command("/does/not/exist", "--flag"))
+		require.Error(t, err)
+		assert.Equal(t, "failed to start command", err.(werror.Werror).Message())
+		binary, _ := werror.ParamFromError(err, "binary")
+		assert.Equal(t, "exist", binary)
+		_, ok := werror.ParamFromError(err, "exitCode")
+		assert.False(t, ok)
+	})
+
+	t.Run("context done before start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var stdout bytes.Buffer
+		cmd := exec.Command("sh", "-c", "echo hello")
+		cmd.Stdout = &stdout
+		err := werror.RunCommand(ctx, cmd)
+		require.Error(t, err)
+		assert.Equal(t, "failed to start command", err.(werror.Werror).Message())
+		assert.Equal(t, &stdout, cmd.Stdout, "the command is not modified if it is not started")
+		assert.Nil(t, cmd.Stderr)
+	})
+
+	t.Run("context kill", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := werror.RunCommand(ctx, exec.Command("sleep", "10"))
+		require.Error(t, err)
+		assert.Equal(t, "command was killed because its context was done", err.(werror.Werror).Message())
+		signal, _ := werror.ParamFromError(err, "signal")
+		assert.Equal(t, "killed", signal)
+		ctxErr, _ := werror.ParamFromError(err, "contextError")
+		assert.Equal(t, context.DeadlineExceeded.Error(), ctxErr)
+	})
+
+	t.Run("exit around cancellation", func(t *testing.T) {
+		// the context is done at about the time the command exits: the error must reflect what actually happened
+		for i := 0; i < 20; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			err := werror.RunCommand(ctx, exec.Command("sh", "-c", "sleep 0.01; exit 3"))
+			cancel()
+			require.Error(t, err)
+			exitCode, _ := werror.ParamFromError(err, "exitCode")
+			signal, _ := werror.ParamFromError(err, "signal")
+			_, hasContextErr := werror.ParamFromError(err, "contextError")
+			if exitCode == 3 {
+				assert.Equal(t, "command exited with non-zero status", err.(werror.Werror).Message())
+				assert.False(t, hasContextErr)
+			} else {
+				assert.Equal(t, "command was killed because its context was done", err.(werror.Werror).Message())
+				assert.Equal(t, "killed", signal)
+			}
+		}
+	})
+}
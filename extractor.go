@@ -0,0 +1,103 @@
+package werror
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor returns the safe and unsafe params that should be stored on every error created with the provided
+// context, such as a trace ID, tenant ID or request ID stored in the context by middleware. Extractors are invoked
+// every time an error is created using a context variant of the constructors, so they must be fast and safe for
+// concurrent use, and they should return nil maps when the context does not store the values that they extract.
+type ContextExtractor func(ctx context.Context) (safe map[string]interface{}, unsafe map[string]interface{})
+
+type namedExtractor struct {
+	name      string
+	extractor ContextExtractor
+}
+
+var (
+	extractorsMu sync.Mutex
+	// extractors stores an immutable slice that is replaced whenever an extractor is registered or unregistered so that
+	// creating an error only requires an atomic load.
+	extractors atomic.Pointer[[]namedExtractor]
+)
+
+// RegisterContextExtractor registers an extractor whose params are stored on every error created with a context:
+// ErrorWithContextParams, WrapWithContextParams, WithContextParams, ConvertWithContextParams, Errorf, Wrapf, Build and
+// Definition. Extractors are identified by name, typically the import path of the package that registers them from an
+// init function: registering an extractor with a name that is already registered replaces the existing extractor, so
+// registering the same extractor multiple times never extracts its params more than once.
+//
+// Only global registration is supported: a registered extractor applies to the errors created by every package in the
+// process, not just the package that registered it. Extractors that should only apply to some errors must check the
+// context themselves and return nil maps for the other errors.
+//
+// Extractors are invoked in the order in which they were first registered. The wparams parameters stored in the
+// context and the params provided explicitly to the constructor take precedence over the params returned by the
+// extractors.
+//
+// Extractors run again at every level of an error chain that is created with a context, for example every call to
+// WrapWithContextParams, so they must be cheap and free of side effects. Values that are already stored deeper in the
+// chain with the same safety and an equal value are not stored again.
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	var updated []namedExtractor
+	replaced := false
+	if current := extractors.Load(); current != nil {
+		for _, e := range *current {
+			if e.name == name {
+				e.extractor = extractor
+				replaced = true
+			}
+			updated = append(updated, e)
+		}
+	}
+	if !replaced {
+		updated = append(updated, namedExtractor{name: name, extractor: extractor})
+	}
+	extractors.Store(&updated)
+}
+
+// UnregisterContextExtractor unregisters the extractor with the provided name. Does nothing if no extractor with the
+// name is registered.
+func UnregisterContextExtractor(name string) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	current := extractors.Load()
+	if current == nil {
+		return
+	}
+	var updated []namedExtractor
+	for _, e := range *current {
+		if e.name != name {
+			updated = append(updated, e)
+		}
+	}
+	extractors.Store(&updated)
+}
+
+// hasExtractors returns true if any extractor is registered.
+func hasExtractors() bool {
+	current := extractors.Load()
+	return current != nil && len(*current) != 0
+}
+
+// extractParams stores the params returned by the registered extractors for the provided context in values.
+func extractParams(ctx context.Context, values map[string]paramValue) {
+	current := extractors.Load()
+	if current == nil {
+		return
+	}
+	for _, e := range *current {
+		safe, unsafe := e.extractor(ctx)
+		for k, v := range safe {
+			values[k] = paramValue{safe: true, value: v}
+		}
+		for k, v := range unsafe {
+			values[k] = paramValue{safe: false, value: v}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package werror_test
+
+import (
+	"context"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantContextKey struct{}
+
+func tenantExtractor(ctx context.Context) (map[string]interface{}, map[string]interface{}) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{"tenantId": tenant}, map[string]interface{}{"tenantName": "name-" + tenant}
+}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	calls := 0
+	werror.RegisterContextExtractor("werror_test/tenant", func(ctx context.Context) (map[string]interface{}, map[string]interface{}) {
+		calls++
+		return tenantExtractor(ctx)
+	})
+	defer werror.UnregisterContextExtractor("werror_test/tenant")
+	werror.RegisterContextExtractor("werror_test/override", func(ctx context.Context) (map[string]interface{}, map[string]interface{}) {
+		return map[string]interface{}{"requestId": "extracted", "source": "extractor"}, nil
+	})
+	defer werror.UnregisterContextExtractor("werror_test/override")
+
+	ctx := context.WithValue(context.Background(), tenantContextKey{}, "t1")
+	ctx = wparams.ContextWithSafeParam(ctx, "requestId", "wparams")
+
+	for _, currCase := range []struct {
+		name string
+		err  error
+	}{
+		{name: "ErrorWithContextParams", err: werror.ErrorWithContextParams(ctx, "error", werror.SafeParam("source", "explicit"))},
+		{name: "WrapWithContextParams", err: werror.WrapWithContextParams(ctx, werror.Error("inner"), "error", werror.SafeParam("source", "explicit"))},
+		{name: "Errorf", err: werror.Errorf(ctx, "error", werror.SafeParam("source", "explicit"))},
+		{name: "Build", err: werror.Build(ctx).Safe("source", "explicit").New("error")},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			safe, unsafe := werror.ParamsFromError(currCase.err)
+			assert.Equal(t, map[string]interface{}{
+				"tenantId":  "t1",
+				"requestId": "wparams",
+				"source":    "explicit",
+			}, safe)
+			assert.Equal(t, map[string]interface{}{"tenantName": "name-t1"}, unsafe)
+		})
+	}
+
+	// re-registering an extractor with the same name replaces it
+	calls = 0
+	werror.RegisterContextExtractor("werror_test/tenant", tenantExtractor)
+	werror.RegisterContextExtractor("werror_test/tenant", func(ctx context.Context) (map[string]interface{}, map[string]interface{}) {
+		calls++
+		return tenantExtractor(ctx)
+	})
+	_ = werror.ErrorWithContextParams(ctx, "error")
+	assert.Equal(t, 1, calls)
+
+	// extractors are not invoked for the variants without a context
+	calls = 0
+	safe, _ := werror.ParamsFromError(werror.Error("error"))
+	assert.Empty(t, safe)
+	assert.Equal(t, 0, calls)
+
+	werror.UnregisterContextExtractor("werror_test/tenant")
+	safe, unsafe := werror.ParamsFromError(werror.ErrorWithContextParams(ctx, "error"))
+	assert.Equal(t, map[string]interface{}{"requestId": "wparams", "source": "extractor"}, safe)
+	assert.Empty(t, unsafe)
+}
@@ -0,0 +1,64 @@
+package werror
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// maxErrorResponseBytes is the maximum number of bytes of an error response body that are read by FromHTTPResponse.
+const maxErrorResponseBytes = 64 * 1024
+
+// FromHTTPResponse returns an error for the provided HTTP response, or nil if the response does not have an error
+// status code (4xx or 5xx). The response body is read and closed.
+//
+// If the body is a Conjure-style JSON error (see SerializableError), the cause of the returned error has the remote
+// error name as its message and the remote instance ID, stores the remote error name and code as the safe parameters
+// "remoteErrorName" and "remoteErrorCode", and stores the remote parameters as safe parameters since they were marked
+// safe by the server. Otherwise, the cause stores the response body as an unsafe parameter.
+//
+// The returned error does not declare a type, so its category is CategoryInternal: a failure of a dependency is an
+// internal error of the service that called it, and a service that returns the error to its own callers (for example,
+// using NewHTTPHandler) must not respond with the status code of its dependency. Callers that want to propagate the
+// remote error can inspect the remote error code and wrap the error with an appropriate Type.
+//
+// The returned error wraps the remote error and stores the request method and host and the response status code as
+// safe parameters and the request URL as an unsafe parameter.
+func FromHTTPResponse(resp *http.Response) error {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+		_ = resp.Body.Close()
+	}
+
+	var remote error
+	var serializable SerializableError
+	if err := json.Unmarshal(body, &serializable); err == nil && serializable.ErrorCode != "" && serializable.ErrorName != "" {
+		remote = newWerror(serializable.ErrorName, nil,
+			SafeParams(serializable.Parameters),
+			SafeParam("remoteErrorName", serializable.ErrorName),
+			SafeParam("remoteErrorCode", string(serializable.ErrorCode)),
+			InstanceID(serializable.ErrorInstanceID),
+		)
+	} else {
+		remote = newWerror("non-Conjure error response", nil,
+			SafeParam("contentType", resp.Header.Get("Content-Type")),
+			UnsafeParam("responseBody", string(body)),
+		)
+	}
+
+	params := []Param{SafeParam("statusCode", resp.StatusCode)}
+	if req := resp.Request; req != nil {
+		params = append(params, SafeParam("method", req.Method))
+		if req.URL != nil {
+			params = append(params,
+				SafeParam("host", req.URL.Host),
+				UnsafeParam("url", req.URL.String()),
+			)
+		}
+	}
+	return newWerror("http request failed", remote, params...)
+}
@@ -0,0 +1,123 @@
+package werror_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromHTTPResponse(t *testing.T) {
+	server := httptest.NewServer(werror.NewHTTPHandler(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/conjure":
+			return werror.ErrorWithContextParams(r.Context(), "user not found",
+				werror.Type(testNotFoundType),
+				werror.InstanceID("instance-id"),
+				werror.SafeParam("userId", "123"),
+				werror.UnsafeParam("email", "user@example.com"),
+			)
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = io.WriteString(w, "bad gateway")
+			return nil
+		default:
+			_, _ = io.WriteString(w, "ok")
+			return nil
+		}
+	}))
+	defer server.Close()
+
+	t.Run("conjure error", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/conjure?q=secret")
+		require.NoError(t, err)
+		respErr := werror.FromHTTPResponse(resp)
+		require.Error(t, respErr)
+
+		assert.EqualError(t, respErr, "http request failed: Test:UserNotFound")
+		_, ok := werror.TypeFromError(respErr)
+		assert.False(t, ok)
+		assert.Equal(t, werror.CategoryInternal, werror.CategoryFromError(respErr))
+		assert.Equal(t, "instance-id", werror.InstanceIDFromError(respErr))
+
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		safe, unsafe := werror.ParamsFromError(respErr)
+		assert.Equal(t, map[string]interface{}{
+			"userId":          "123",
+			"remoteErrorName": "Test:UserNotFound",
+			"remoteErrorCode": "NOT_FOUND",
+			"statusCode":      http.StatusNotFound,
+			"method":          http.MethodGet,
+			"host":            serverURL.Host,
+		}, safe)
+		assert.Equal(t, map[string]interface{}{
+			"url": server.URL + "/conjure?q=secret",
+		}, unsafe)
+	})
+
+	t.Run("non-JSON error", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/text")
+		require.NoError(t, err)
+		respErr := werror.FromHTTPResponse(resp)
+		require.Error(t, respErr)
+
+		assert.EqualError(t, respErr, "http request failed: non-Conjure error response")
+		_, ok := werror.TypeFromError(respErr)
+		assert.False(t, ok)
+		body, safe := werror.ParamFromError(respErr, "responseBody")
+		assert.Equal(t, "bad gateway", body)
+		assert.False(t, safe)
+		contentType, safe := werror.ParamFromError(respErr, "contentType")
+		assert.Equal(t, "text/plain", contentType)
+		assert.True(t, safe)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/ok")
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		assert.NoError(t, werror.FromHTTPResponse(resp))
+	})
+
+	t.Run("round trip through wrapping server", func(t *testing.T) {
+		// a server that returns the remote error responds with an internal error, but preserves the instance ID
+		resp, err := http.Get(server.URL + "/conjure")
+		require.NoError(t, err)
+		remoteErr := werror.WrapWithContextParams(context.Background(), werror.FromHTTPResponse(resp), "proxy failed")
+
+		rec := httptest.NewRecorder()
+		werror.WriteHTTPError(rec, remoteErr)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.True(t, strings.Contains(rec.Body.String(), `"errorName":"Default:Internal"`))
+		assert.True(t, strings.Contains(rec.Body.String(), `"errorInstanceId":"instance-id"`))
+	})
+
+	t.Run("explicit propagation", func(t *testing.T) {
+		// callers can opt in to propagating the remote error by declaring a type based on the remote error code
+		resp, err := http.Get(server.URL + "/conjure")
+		require.NoError(t, err)
+		respErr := werror.FromHTTPResponse(resp)
+		code, _ := werror.ParamFromError(respErr, "remoteErrorCode")
+		require.Equal(t, string(werror.CategoryNotFound), code)
+		propagated := werror.WrapWithContextParams(context.Background(), respErr, "user not found", werror.Type(testNotFoundType))
+
+		rec := httptest.NewRecorder()
+		werror.WriteHTTPError(rec, propagated)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("nil response", func(t *testing.T) {
+		assert.NoError(t, werror.FromHTTPResponse(nil))
+	})
+}
@@ -0,0 +1,185 @@
+package werror
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+)
+
+// SerializableError is the JSON representation of an error as defined by the Conjure wire specification. It only ever
+// contains the safe parameters of an error.
+type SerializableError struct {
+	ErrorCode       Category               `json:"errorCode"`
+	ErrorName       string                 `json:"errorName"`
+	ErrorInstanceID string                 `json:"errorInstanceId"`
+	Parameters      map[string]interface{} `json:"parameters"`
+}
+
+// NewSerializableError returns the SerializableError for the provided error. Errors that do not declare a type are
+// serialized as DefaultInternal. If the error does not have an instance ID, a new one is generated.
+func NewSerializableError(err error) SerializableError {
+	errorType, ok := TypeFromError(err)
+	if !ok {
+		errorType = DefaultInternal
+	}
+	instanceID := InstanceIDFromError(err)
+	if instanceID == "" {
+		instanceID = newInstanceID()
+	}
+	safe, _ := ParamsFromError(err)
+	return SerializableError{
+		ErrorCode:       errorType.Category(),
+		ErrorName:       errorType.Name(),
+		ErrorInstanceID: instanceID,
+		Parameters:      safe,
+	}
+}
+
+// WriteHTTPError writes the provided error to w as a Conjure-style JSON error response. The status code of the
+// response is determined by the category of the error and the body only includes its safe parameters.
+//
+// If the error does not have an instance ID, a new one is generated for the response. Use NewHTTPHandler or
+// NewHTTPMiddleware to ensure that the instance ID in the response is also visible to an HTTPErrorHook.
+func WriteHTTPError(w http.ResponseWriter, err error) {
+	serializable := NewSerializableError(err)
+	body, marshalErr := json.Marshal(serializable)
+	if marshalErr != nil {
+		// safe params may not be serializable: fall back to a response without parameters
+		serializable.Parameters = nil
+		body, _ = json.Marshal(serializable)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(serializable.ErrorCode.StatusCode())
+	_, _ = w.Write(body)
+}
+
+// HTTPHandlerFunc is an HTTP handler function that returns an error.
+type HTTPHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// HTTPErrorHook is invoked with the full error whenever an error is written as an HTTP response. It is typically used
+// to log the error, including its unsafe parameters and stack trace, or to record metrics. The statusCode is the
+// status code of the response, or 0 if the handler had already written a response before the error occurred.
+type HTTPErrorHook func(r *http.Request, statusCode int, err error)
+
+// HTTPServerOption configures NewHTTPHandler and NewHTTPMiddleware.
+type HTTPServerOption func(*httpServerConfig)
+
+type httpServerConfig struct {
+	hooks []HTTPErrorHook
+}
+
+// WithHTTPErrorHook adds a hook that is invoked for every error written as an HTTP response.
+func WithHTTPErrorHook(hook HTTPErrorHook) HTTPServerOption {
+	return func(cfg *httpServerConfig) {
+		cfg.hooks = append(cfg.hooks, hook)
+	}
+}
+
+// NewHTTPHandler returns an http.Handler that invokes fn and writes any error that it returns using WriteHTTPError.
+// Panics in fn are recovered and handled as errors.
+func NewHTTPHandler(fn HTTPHandlerFunc, options ...HTTPServerOption) http.Handler {
+	cfg := newHTTPServerConfig(options)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &httpResponseWriter{ResponseWriter: w}
+		defer cfg.recoverPanic(rw, r)
+		if err := fn(rw, r); err != nil {
+			cfg.handleError(rw, r, err)
+		}
+	})
+}
+
+// NewHTTPMiddleware returns middleware that recovers panics in the wrapped handler and writes them as errors using
+// WriteHTTPError.
+func NewHTTPMiddleware(options ...HTTPServerOption) func(http.Handler) http.Handler {
+	cfg := newHTTPServerConfig(options)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &httpResponseWriter{ResponseWriter: w}
+			defer cfg.recoverPanic(rw, r)
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func newHTTPServerConfig(options []HTTPServerOption) *httpServerConfig {
+	cfg := &httpServerConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg
+}
+
+func (cfg *httpServerConfig) recoverPanic(w *httpResponseWriter, r *http.Request) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered == http.ErrAbortHandler {
+		// ErrAbortHandler is used to abort a response and is handled by the server
+		panic(recovered)
+	}
+	var err error
+	if recoveredErr, ok := recovered.(error); ok {
+		err = WrapWithContextParams(r.Context(), recoveredErr, "panic recovered")
+	} else {
+		err = ErrorWithContextParams(r.Context(), "panic recovered", UnsafeParam("recovered", recovered))
+	}
+	cfg.handleError(w, r, err)
+}
+
+func (cfg *httpServerConfig) handleError(w *httpResponseWriter, r *http.Request, err error) {
+	if InstanceIDFromError(err) == "" {
+		// assign the instance ID before writing so that the hooks observe the same instance ID as the client. The
+		// annotation does not add a message or a stack trace, so the hooks observe the chain returned by the handler.
+		err = WithParams(err, InstanceID(newInstanceID()))
+	}
+	statusCode := 0
+	if !w.wroteHeader {
+		statusCode = CategoryFromError(err).StatusCode()
+		WriteHTTPError(w, err)
+	}
+	for _, hook := range cfg.hooks {
+		hook(r, statusCode, err)
+	}
+}
+
+// httpResponseWriter records whether a response has been written so that errors are not written after a handler has
+// already started its response.
+type httpResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *httpResponseWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *httpResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so that streaming handlers can flush their response. Does nothing if the underlying
+// http.ResponseWriter does not support flushing.
+func (w *httpResponseWriter) Flush() {
+	if err := http.NewResponseController(w.ResponseWriter).Flush(); err == nil {
+		w.wroteHeader = true
+	}
+}
+
+// Hijack implements http.Hijacker. Returns an error if the underlying http.ResponseWriter does not support hijacking.
+// Errors are never written to a hijacked connection.
+func (w *httpResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter. Exists to support http.ResponseController.
+func (w *httpResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,199 @@
+package werror_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTTPError(t *testing.T) {
+	for _, currCase := range []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   werror.Category
+		wantName   string
+		wantParams map[string]interface{}
+	}{
+		{
+			name:       "untyped error",
+			err:        werror.ErrorWithContextParams(context.Background(), "failed", werror.SafeParam("safeKey", "safeValue"), werror.UnsafeParam("unsafeKey", "unsafeValue")),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   werror.CategoryInternal,
+			wantName:   "Default:Internal",
+			wantParams: map[string]interface{}{"safeKey": "safeValue"},
+		},
+		{
+			name: "wrapped typed error",
+			err: werror.WrapWithContextParams(context.Background(),
+				werror.ErrorWithContextParams(context.Background(), "user not found", werror.Type(testNotFoundType), werror.SafeParam("userId", "123")),
+				"failed to handle request",
+				werror.UnsafeParam("path", "/users/123"),
+			),
+			wantStatus: http.StatusNotFound,
+			wantCode:   werror.CategoryNotFound,
+			wantName:   "Test:UserNotFound",
+			wantParams: map[string]interface{}{"userId": "123"},
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			werror.WriteHTTPError(rec, currCase.err)
+			assert.Equal(t, currCase.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var body werror.SerializableError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, currCase.wantCode, body.ErrorCode)
+			assert.Equal(t, currCase.wantName, body.ErrorName)
+			assert.NotEmpty(t, body.ErrorInstanceID)
+			assert.Equal(t, currCase.wantParams, body.Parameters)
+		})
+	}
+}
+
+func TestNewHTTPHandler(t *testing.T) {
+	var hookErr error
+	var hookStatus int
+	hook := werror.WithHTTPErrorHook(func(r *http.Request, statusCode int, err error) {
+		hookStatus = statusCode
+		hookErr = err
+	})
+
+	t.Run("returned error", func(t *testing.T) {
+		handler := werror.NewHTTPHandler(func(w http.ResponseWriter, r *http.Request) error {
+			return werror.ErrorWithContextParams(r.Context(), "user not found", werror.Type(testNotFoundType))
+		}, hook)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/123", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		var body werror.SerializableError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, http.StatusNotFound, hookStatus)
+		assert.EqualError(t, hookErr, "user not found")
+		assert.Equal(t, werror.InstanceIDFromError(hookErr), body.ErrorInstanceID)
+	})
+
+	t.Run("untyped error gets instance ID visible to hook", func(t *testing.T) {
+		var handlerErr error
+		handler := werror.NewHTTPHandler(func(w http.ResponseWriter, r *http.Request) error {
+			handlerErr = werror.ErrorWithContextParams(r.Context(), "failed")
+			return handlerErr
+		}, hook)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		var body werror.SerializableError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.NotEmpty(t, body.ErrorInstanceID)
+		assert.Equal(t, werror.InstanceIDFromError(hookErr), body.ErrorInstanceID)
+		assert.EqualError(t, hookErr, "failed")
+		// the instance ID is stored on an annotation of the returned error
+		assert.Equal(t, handlerErr, hookErr.(werror.Werror).Cause())
+		assert.Nil(t, hookErr.(werror.Werror).StackTrace())
+		assert.Equal(t, "failed", fmt.Sprintf("%v", hookErr))
+	})
+
+	t.Run("error after response was written", func(t *testing.T) {
+		handler := werror.NewHTTPHandler(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusAccepted)
+			return werror.ErrorWithContextParams(r.Context(), "failed late")
+		}, hook)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, 0, hookStatus)
+		assert.EqualError(t, hookErr, "failed late")
+	})
+}
+
+func TestNewHTTPMiddleware(t *testing.T) {
+	var hookErr error
+	middleware := werror.NewHTTPMiddleware(werror.WithHTTPErrorHook(func(r *http.Request, statusCode int, err error) {
+		hookErr = err
+	}))
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(wparams.ContextWithSafeParam(req.Context(), "requestId", "abc"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var body werror.SerializableError
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "Default:Internal", body.ErrorName)
+	assert.Equal(t, map[string]interface{}{"requestId": "abc"}, body.Parameters)
+
+	require.EqualError(t, hookErr, "panic recovered")
+	recovered, safe := werror.ParamFromError(hookErr, "recovered")
+	assert.Equal(t, "boom", recovered)
+	assert.False(t, safe)
+}
+
+func TestNewHTTPMiddleware_Flush(t *testing.T) {
+	var hookStatusCode int
+	middleware := werror.NewHTTPMiddleware(werror.WithHTTPErrorHook(func(r *http.Request, statusCode int, err error) {
+		hookStatusCode = statusCode
+	}))
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		flusher.Flush()
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, rec.Flushed)
+	// the response was started by the flush, so the error is not written
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, 0, hookStatusCode)
+}
+
+func TestNewHTTPMiddleware_Hijack(t *testing.T) {
+	middleware := werror.NewHTTPMiddleware()
+	server := httptest.NewServer(middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		require.True(t, ok)
+		conn, rw, err := hijacker.Hijack()
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = rw.Flush()
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hijacked", string(body))
+
+	// hijacking is not supported by the recorder
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err := w.(http.Hijacker).Hijack()
+		assert.ErrorIs(t, err, http.ErrNotSupported)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,148 @@
+package werror
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Fault configures the error returned by Inject for an injection point.
+type Fault struct {
+	// Message is the message of the injected error. Defaults to "injected fault".
+	Message string
+	// Params are the params of the injected error, such as the params returned by Type or Retryable.
+	Params []Param
+	// Every configures the fault to only be injected on every Nth call of the injection point, starting with the Nth
+	// call. Values less than or equal to 1 inject the fault on every call.
+	Every int
+	// Panic configures the injection point to panic with the injected error instead of returning it.
+	Panic bool
+}
+
+// FaultRegistry stores the faults for named injection points and counts the calls of the injection points. A registry
+// only affects Inject when it is stored in the context using ContextWithFaultRegistry or set as the global registry
+// using SetGlobalFaultRegistry. It is safe for concurrent use.
+type FaultRegistry struct {
+	mu     sync.Mutex
+	points map[string]*injectionPoint
+}
+
+type injectionPoint struct {
+	fault    *Fault
+	calls    int
+	injected int
+}
+
+// NewFaultRegistry returns a new empty FaultRegistry.
+func NewFaultRegistry() *FaultRegistry {
+	return &FaultRegistry{
+		points: make(map[string]*injectionPoint),
+	}
+}
+
+// Set configures the fault for the injection point with the provided name.
+func (r *FaultRegistry) Set(name string, fault Fault) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.point(name).fault = &fault
+}
+
+// Clear removes the fault for the injection point with the provided name. The counters of the point are not reset.
+func (r *FaultRegistry) Clear(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.point(name).fault = nil
+}
+
+// Calls returns the number of times the injection point with the provided name was called while this registry was
+// active, regardless of whether a fault was injected.
+func (r *FaultRegistry) Calls(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.point(name).calls
+}
+
+// Injected returns the number of times a fault was injected by the injection point with the provided name.
+func (r *FaultRegistry) Injected(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.point(name).injected
+}
+
+func (r *FaultRegistry) point(name string) *injectionPoint {
+	p, ok := r.points[name]
+	if !ok {
+		p = &injectionPoint{}
+		r.points[name] = p
+	}
+	return p
+}
+
+// call records a call of the injection point with the provided name and returns the fault to inject, if any.
+func (r *FaultRegistry) call(name string) (Fault, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.point(name)
+	p.calls++
+	if p.fault == nil {
+		return Fault{}, false
+	}
+	if every := p.fault.Every; every > 1 && p.calls%every != 0 {
+		return Fault{}, false
+	}
+	p.injected++
+	return *p.fault, true
+}
+
+type faultRegistryContextKey struct{}
+
+// ContextWithFaultRegistry returns a copy of the provided context that stores the provided registry. Inject uses the
+// registry stored in its context in preference to the global registry.
+func ContextWithFaultRegistry(ctx context.Context, registry *FaultRegistry) context.Context {
+	return context.WithValue(ctx, faultRegistryContextKey{}, registry)
+}
+
+var globalFaultRegistry atomic.Pointer[FaultRegistry]
+
+// SetGlobalFaultRegistry sets the registry used by Inject when its context does not store a registry and returns a
+// function that restores the previous global registry. It is intended to be used in tests for code paths that do not
+// propagate a context from the test, typically as t.Cleanup(werror.SetGlobalFaultRegistry(registry)). Tests that set
+// the global registry must not run in parallel with other tests that inject faults.
+func SetGlobalFaultRegistry(registry *FaultRegistry) (restore func()) {
+	previous := globalFaultRegistry.Swap(registry)
+	return func() {
+		globalFaultRegistry.Store(previous)
+	}
+}
+
+// Inject is a named fault injection point. It returns nil unless a FaultRegistry is stored in the provided context or
+// set as the global registry and the registry has a fault for the injection point that should be injected on this call.
+// In that case, the returned error has the configured message and params, the wparams parameters stored in the context
+// and a safe "faultName" param, or Inject panics with the error if the fault is configured to panic. Inject is cheap
+// when no registry is active, so injection points can remain in production code:
+//
+//	if err := werror.Inject(ctx, "repo.getUser"); err != nil {
+//		return err
+//	}
+func Inject(ctx context.Context, name string) error {
+	registry, _ := ctx.Value(faultRegistryContextKey{}).(*FaultRegistry)
+	if registry == nil {
+		if registry = globalFaultRegistry.Load(); registry == nil {
+			return nil
+		}
+	}
+	fault, ok := registry.call(name)
+	if !ok {
+		return nil
+	}
+	message := fault.Message
+	if message == "" {
+		message = "injected fault"
+	}
+	params := append([]Param{SafeParam("faultName", name)}, fault.Params...)
+	err := newWerrorWithContext(ctx, message, nil, params...)
+	if fault.Panic {
+		panic(err)
+	}
+	return err
+}
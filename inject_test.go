@@ -0,0 +1,103 @@
+package werror_test
+
+import (
+	"context"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInject(t *testing.T) {
+	assert.NoError(t, werror.Inject(context.Background(), "repo.getUser"), "no registry")
+
+	registry := werror.NewFaultRegistry()
+	ctx := werror.ContextWithFaultRegistry(context.Background(), registry)
+	assert.NoError(t, werror.Inject(ctx, "repo.getUser"), "no fault")
+
+	registry.Set("repo.getUser", werror.Fault{
+		Message: "database unavailable",
+		Params: []werror.Param{
+			werror.Type(werror.DefaultTimeout),
+			werror.Retryable(true),
+			werror.UnsafeParam("host", "db-1"),
+		},
+	})
+	err := werror.Inject(ctx, "repo.getUser")
+	require.Error(t, err)
+	assert.EqualError(t, err, "database unavailable")
+	assert.Equal(t, werror.CategoryTimeout, werror.CategoryFromError(err))
+	assert.True(t, werror.IsRetryable(err))
+	safe, unsafe := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{"faultName": "repo.getUser"}, safe)
+	assert.Equal(t, map[string]interface{}{"host": "db-1"}, unsafe)
+	assert.NoError(t, werror.Inject(ctx, "repo.listUsers"), "other injection point")
+
+	registry.Clear("repo.getUser")
+	assert.NoError(t, werror.Inject(ctx, "repo.getUser"))
+
+	assert.Equal(t, 3, registry.Calls("repo.getUser"))
+	assert.Equal(t, 1, registry.Injected("repo.getUser"))
+	assert.Equal(t, 1, registry.Calls("repo.listUsers"))
+	assert.Equal(t, 0, registry.Injected("repo.listUsers"))
+}
+
+func TestInjectEvery(t *testing.T) {
+	registry := werror.NewFaultRegistry()
+	registry.Set("cache.get", werror.Fault{Every: 3})
+	ctx := werror.ContextWithFaultRegistry(context.Background(), registry)
+
+	var failed []int
+	for i := 1; i <= 7; i++ {
+		if err := werror.Inject(ctx, "cache.get"); err != nil {
+			assert.EqualError(t, err, "injected fault")
+			failed = append(failed, i)
+		}
+	}
+	assert.Equal(t, []int{3, 6}, failed)
+	assert.Equal(t, 7, registry.Calls("cache.get"))
+	assert.Equal(t, 2, registry.Injected("cache.get"))
+}
+
+func TestInjectPanic(t *testing.T) {
+	registry := werror.NewFaultRegistry()
+	registry.Set("worker.run", werror.Fault{Panic: true})
+	ctx := werror.ContextWithFaultRegistry(context.Background(), registry)
+
+	defer func() {
+		recovered := recover()
+		err, ok := recovered.(error)
+		require.True(t, ok, "expected panic with error, got %v", recovered)
+		assert.EqualError(t, err, "injected fault")
+		assert.Equal(t, 1, registry.Injected("worker.run"))
+	}()
+	_ = werror.Inject(ctx, "worker.run")
+	t.Fatal("expected panic")
+}
+
+func TestInjectGlobalRegistry(t *testing.T) {
+	global := werror.NewFaultRegistry()
+	global.Set("repo.getUser", werror.Fault{})
+	restore := werror.SetGlobalFaultRegistry(global)
+
+	assert.Error(t, werror.Inject(context.Background(), "repo.getUser"))
+
+	// the registry in the context takes precedence over the global registry
+	local := werror.NewFaultRegistry()
+	assert.NoError(t, werror.Inject(werror.ContextWithFaultRegistry(context.Background(), local), "repo.getUser"))
+	assert.Equal(t, 1, global.Calls("repo.getUser"))
+	assert.Equal(t, 1, local.Calls("repo.getUser"))
+
+	restore()
+	assert.NoError(t, werror.Inject(context.Background(), "repo.getUser"))
+	assert.Equal(t, 1, global.Calls("repo.getUser"))
+}
+
+func BenchmarkInjectDisabled(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = werror.Inject(ctx, "repo.getUser")
+	}
+}
@@ -0,0 +1,185 @@
+package werror
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Observer is invoked with a view of every werror created by this package, including the errors created by Convert
+// and by the context variants of the constructors. Observers are typically used to record metrics or to add errors to
+// tracing spans without modifying call sites.
+//
+// Observers are invoked synchronously on the goroutine that creates the error, after the error is fully constructed and
+// before it is returned to the caller, in the order in which they were registered. Because they are invoked for every
+// error, observers must be fast, must not block and must be safe for concurrent use. Observers should not panic: a
+// panic propagates to the code that created the error.
+//
+// Errors created by an observer, directly or indirectly, are also passed to the observers, so an observer that creates
+// errors must guard against unbounded recursion.
+type Observer func(e CreatedError)
+
+// CreatedError is an immutable view of a newly created werror that is passed to observers.
+type CreatedError struct {
+	err *werror
+	ctx context.Context
+}
+
+// Err returns the created error.
+func (e CreatedError) Err() error {
+	return e.err
+}
+
+// Message returns the message of the created error.
+func (e CreatedError) Message() string {
+	return e.err.message
+}
+
+// Cause returns the cause of the created error, or nil if it does not have a cause.
+func (e CreatedError) Cause() error {
+	return e.err.cause
+}
+
+// SafeParams returns a copy of the safe params stored on the created error, not including the params of its causes.
+func (e CreatedError) SafeParams() map[string]interface{} {
+	return e.levelParams(true)
+}
+
+// UnsafeParams returns a copy of the unsafe params stored on the created error, not including the params of its causes.
+func (e CreatedError) UnsafeParams() map[string]interface{} {
+	return e.levelParams(false)
+}
+
+func (e CreatedError) levelParams(safe bool) map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, p := range e.err.params {
+		if p.safe == safe {
+			params[p.key] = p.value
+		}
+	}
+	return params
+}
+
+// Type returns the type declared by the created error, not including the types declared by its causes. Returns false
+// if the created error does not declare a type.
+func (e CreatedError) Type() (ErrorType, bool) {
+	return e.err.errorType, !e.err.errorType.IsZero()
+}
+
+// Caller returns the frame of the function that created the error. Returns false if the created error does not have a
+// stack trace, such as the errors created by WithParams. The frame is resolved when this function is called, so
+// observers that do not need it do not pay for symbolization.
+func (e CreatedError) Caller() (runtime.Frame, bool) {
+	st, ok := e.err.stack.(*stack)
+	if !ok || st == nil || len(*st) == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames((*st)[:1]).Next()
+	return frame, true
+}
+
+// Context returns the context provided to the function that created the error, or nil if the error was created by a
+// function that does not take a context.
+func (e CreatedError) Context() context.Context {
+	return e.ctx
+}
+
+type registeredObserver struct {
+	id       uint64
+	observer Observer
+}
+
+var (
+	observersMu    sync.Mutex
+	nextObserverID uint64
+	// observers stores an immutable slice that is replaced whenever an observer is registered or unregistered so that
+	// creating an error only requires an atomic load.
+	observers atomic.Pointer[[]registeredObserver]
+)
+
+// RegisterObserver registers the provided observer and returns a function that unregisters it. Registering or
+// unregistering an observer affects the errors created after the function returns.
+func RegisterObserver(observer Observer) (unregister func()) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	nextObserverID++
+	id := nextObserverID
+	var updated []registeredObserver
+	if current := observers.Load(); current != nil {
+		updated = append(updated, *current...)
+	}
+	updated = append(updated, registeredObserver{id: id, observer: observer})
+	observers.Store(&updated)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			observersMu.Lock()
+			defer observersMu.Unlock()
+			var remaining []registeredObserver
+			for _, o := range *observers.Load() {
+				if o.id != id {
+					remaining = append(remaining, o)
+				}
+			}
+			observers.Store(&remaining)
+		})
+	}
+}
+
+// notifyObservers invokes the registered observers for the provided newly created error.
+func notifyObservers(we *werror, ctx context.Context) {
+	current := observers.Load()
+	if current == nil || len(*current) == 0 {
+		return
+	}
+	created := CreatedError{err: we, ctx: ctx}
+	for _, o := range *current {
+		o.observer(created)
+	}
+}
+
+// FingerprintCounter is an Observer that counts the created errors by fingerprint. The fingerprint of an error is its
+// declared type name (or the empty string) and its message joined by "|", so that errors created using constant
+// messages have a bounded number of fingerprints. Errors with an empty message, such as the ones created by
+// WithParams and Convert, are not counted since they only annotate another error.
+type FingerprintCounter struct {
+	counts sync.Map
+}
+
+// NewFingerprintCounter returns a new FingerprintCounter. Register it using RegisterObserver(counter.Observe).
+func NewFingerprintCounter() *FingerprintCounter {
+	return &FingerprintCounter{}
+}
+
+// Observe counts the provided error. It is an Observer.
+func (c *FingerprintCounter) Observe(e CreatedError) {
+	if e.Message() == "" {
+		return
+	}
+	fingerprint := e.err.errorType.Name() + "|" + e.Message()
+	count, ok := c.counts.Load(fingerprint)
+	if !ok {
+		count, _ = c.counts.LoadOrStore(fingerprint, new(int64))
+	}
+	atomic.AddInt64(count.(*int64), 1)
+}
+
+// Count returns the number of errors counted for the provided fingerprint.
+func (c *FingerprintCounter) Count(fingerprint string) int64 {
+	if count, ok := c.counts.Load(fingerprint); ok {
+		return atomic.LoadInt64(count.(*int64))
+	}
+	return 0
+}
+
+// Counts returns a snapshot of the counts of all fingerprints.
+func (c *FingerprintCounter) Counts() map[string]int64 {
+	counts := make(map[string]int64)
+	c.counts.Range(func(fingerprint, count interface{}) bool {
+		counts[fingerprint.(string)] = atomic.LoadInt64(count.(*int64))
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,101 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testContextKey struct{}
+
+func TestRegisterObserver(t *testing.T) {
+	var order []string
+	var created []werror.CreatedError
+	unregisterFirst := werror.RegisterObserver(func(e werror.CreatedError) {
+		order = append(order, "first")
+		created = append(created, e)
+	})
+	unregisterSecond := werror.RegisterObserver(func(e werror.CreatedError) {
+		order = append(order, "second")
+	})
+
+	ctx := context.WithValue(context.Background(), testContextKey{}, "value")
+	cause := errors.New("cause")
+	err := werror.WrapWithContextParams(ctx, cause, "failed",
+		werror.SafeParam("safeKey", "safeValue"),
+		werror.UnsafeParam("unsafeKey", "unsafeValue"),
+		werror.Type(testNotFoundType),
+	)
+	converted := werror.Convert(errors.New("converted"))
+	unregisterFirst()
+	unregisterFirst()
+	_ = werror.Error("only second")
+	unregisterSecond()
+	_ = werror.Error("none")
+
+	assert.Equal(t, []string{"first", "second", "first", "second", "second"}, order)
+	require.Len(t, created, 2)
+
+	e := created[0]
+	assert.Equal(t, err, e.Err())
+	assert.Equal(t, "failed", e.Message())
+	assert.Equal(t, cause, e.Cause())
+	assert.Equal(t, map[string]interface{}{"safeKey": "safeValue"}, e.SafeParams())
+	assert.Equal(t, map[string]interface{}{"unsafeKey": "unsafeValue"}, e.UnsafeParams())
+	errorType, ok := e.Type()
+	assert.True(t, ok)
+	assert.Equal(t, testNotFoundType, errorType)
+	require.NotNil(t, e.Context())
+	assert.Equal(t, "value", e.Context().Value(testContextKey{}))
+	frame, ok := e.Caller()
+	require.True(t, ok)
+	assert.Equal(t, "github.com/palantir/witchcraft-go-error_test.TestRegisterObserver", frame.Function)
+
+	// views are immutable
+	e.SafeParams()["safeKey"] = "modified"
+	safe, _ := werror.ParamsFromError(err)
+	assert.Equal(t, "safeValue", safe["safeKey"])
+
+	e = created[1]
+	assert.Equal(t, converted, e.Err())
+	assert.Equal(t, "", e.Message())
+	assert.Nil(t, e.Context())
+	_, ok = e.Type()
+	assert.False(t, ok)
+}
+
+func TestFingerprintCounter(t *testing.T) {
+	counter := werror.NewFingerprintCounter()
+	unregister := werror.RegisterObserver(counter.Observe)
+	defer unregister()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_ = werror.ErrorWithContextParams(ctx, "user not found", werror.Type(testNotFoundType), werror.SafeParam("attempt", i))
+	}
+	inner := werror.ErrorWithContextParams(ctx, "connection refused")
+	_ = werror.WithParams(inner, werror.SafeParam("host", "db"))
+	_ = werror.Convert(errors.New("converted"))
+
+	assert.Equal(t, int64(3), counter.Count("Test:UserNotFound|user not found"))
+	assert.Equal(t, int64(0), counter.Count("missing"))
+	assert.Equal(t, map[string]int64{
+		"Test:UserNotFound|user not found": 3,
+		"|connection refused":              1,
+	}, counter.Counts())
+}
+
+func BenchmarkErrorWithObserver(b *testing.B) {
+	ctx := context.Background()
+	unregister := werror.RegisterObserver(werror.NewFingerprintCounter().Observe)
+	defer unregister()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = werror.ErrorWithContextParams(ctx, "error", werror.SafeParam("key", "value"))
+	}
+}
@@ -15,7 +15,7 @@ func (p param) apply(e *werror) {
 }
 
 func SafeParam(key string, val interface{}) Param {
-	return SafeParams(map[string]interface{}{key: val})
+	return storedParam{key: key, paramValue: paramValue{safe: true, value: val}}
 }
 
 func SafeParams(vals map[string]interface{}) Param {
@@ -23,7 +23,7 @@ func SafeParams(vals map[string]interface{}) Param {
 }
 
 func UnsafeParam(key string, val interface{}) Param {
-	return UnsafeParams(map[string]interface{}{key: val})
+	return storedParam{key: key, paramValue: paramValue{safe: false, value: val}}
 }
 
 func UnsafeParams(vals map[string]interface{}) Param {
@@ -33,10 +33,7 @@ func UnsafeParams(vals map[string]interface{}) Param {
 func paramsHelper(vals map[string]interface{}, safe bool) Param {
 	return param(func(z *werror) {
 		for k, v := range vals {
-			z.params[k] = paramValue{
-				safe:  safe,
-				value: v,
-			}
+			z.setParam(k, safe, v)
 		}
 	})
 }
@@ -0,0 +1,46 @@
+package werror
+
+import (
+	"sync"
+)
+
+var (
+	errorTypeRegistryMu sync.RWMutex
+	errorTypeRegistry   = make(map[string]ErrorType)
+)
+
+// RegisterErrorType registers the provided error type so that it can be looked up by name using
+// RegisteredErrorType. Registering the same error type multiple times is allowed. Panics if the error type is the zero
+// value or if a different error type with the same name was already registered. It is intended to be called from init
+// functions, such as the ones generated by werror-gen.
+func RegisterErrorType(errorType ErrorType) {
+	if errorType.IsZero() {
+		panic("werror: cannot register the zero ErrorType")
+	}
+	errorTypeRegistryMu.Lock()
+	defer errorTypeRegistryMu.Unlock()
+	if registered, ok := errorTypeRegistry[errorType.Name()]; ok && registered != errorType {
+		panic("werror: error type " + errorType.Name() + " is already registered with category " + string(registered.Category()))
+	}
+	errorTypeRegistry[errorType.Name()] = errorType
+}
+
+// RegisteredErrorType returns the registered error type with the provided name. Returns false if no error type with
+// the name was registered.
+func RegisteredErrorType(name string) (ErrorType, bool) {
+	errorTypeRegistryMu.RLock()
+	defer errorTypeRegistryMu.RUnlock()
+	errorType, ok := errorTypeRegistry[name]
+	return errorType, ok
+}
+
+// RegisteredErrorTypes returns all registered error types.
+func RegisteredErrorTypes() []ErrorType {
+	errorTypeRegistryMu.RLock()
+	defer errorTypeRegistryMu.RUnlock()
+	errorTypes := make([]ErrorType, 0, len(errorTypeRegistry))
+	for _, errorType := range errorTypeRegistry {
+		errorTypes = append(errorTypes, errorType)
+	}
+	return errorTypes
+}
@@ -0,0 +1,29 @@
+package werror_test
+
+import (
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterErrorType(t *testing.T) {
+	registered := werror.MustErrorType(werror.CategoryConflict, "Registry:Registered")
+	werror.RegisterErrorType(registered)
+	werror.RegisterErrorType(registered)
+
+	got, ok := werror.RegisteredErrorType("Registry:Registered")
+	assert.True(t, ok)
+	assert.Equal(t, registered, got)
+	assert.Contains(t, werror.RegisteredErrorTypes(), registered)
+
+	_, ok = werror.RegisteredErrorType("Registry:Unknown")
+	assert.False(t, ok)
+
+	assert.PanicsWithValue(t, "werror: error type Registry:Registered is already registered with category CONFLICT", func() {
+		werror.RegisterErrorType(werror.MustErrorType(werror.CategoryNotFound, "Registry:Registered"))
+	})
+	assert.Panics(t, func() {
+		werror.RegisterErrorType(werror.ErrorType{})
+	})
+}
@@ -0,0 +1,38 @@
+package werror
+
+// retryHint records whether an error declared that the operation that produced it can be retried.
+type retryHint int8
+
+const (
+	retryUnset retryHint = iota
+	retryAllowed
+	retryNotAllowed
+)
+
+// Retryable returns a Param that declares whether the operation that produced the error can be retried.
+func Retryable(retryable bool) Param {
+	return param(func(z *werror) {
+		if retryable {
+			z.retryable = retryAllowed
+		} else {
+			z.retryable = retryNotAllowed
+		}
+	})
+}
+
+// IsRetryable returns true if the provided error or any of its causes declares that it is retryable. If multiple errors
+// in the chain declare whether they are retryable, the outermost declaration wins. Returns false if no error in the
+// chain declares whether it is retryable.
+func IsRetryable(err error) bool {
+	for currErr := err; currErr != nil; {
+		if we, ok := currErr.(*werror); ok && we.retryable != retryUnset {
+			return we.retryable == retryAllowed
+		}
+		causer, ok := currErr.(Causer)
+		if !ok {
+			return false
+		}
+		currErr = causer.Cause()
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package werror_test
+
+import (
+	"errors"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	for _, currCase := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "non-werror",
+			err:  errors.New("failed"),
+			want: false,
+		},
+		{
+			name: "undeclared",
+			err:  werror.Error("failed"),
+			want: false,
+		},
+		{
+			name: "retryable",
+			err:  werror.Error("failed", werror.Retryable(true)),
+			want: true,
+		},
+		{
+			name: "not retryable",
+			err:  werror.Error("failed", werror.Retryable(false)),
+			want: false,
+		},
+		{
+			name: "declared by cause",
+			err:  werror.Wrap(werror.Error("failed", werror.Retryable(true)), "wrapped"),
+			want: true,
+		},
+		{
+			name: "outermost declaration wins over retryable cause",
+			err:  werror.Wrap(werror.Error("failed", werror.Retryable(true)), "wrapped", werror.Retryable(false)),
+			want: false,
+		},
+		{
+			name: "outermost declaration wins over non-retryable cause",
+			err:  werror.Wrap(werror.Error("failed", werror.Retryable(false)), "wrapped", werror.Retryable(true)),
+			want: true,
+		},
+		{
+			name: "last declaration at the same level wins",
+			err:  werror.Error("failed", werror.Retryable(true), werror.Retryable(false)),
+			want: false,
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			assert.Equal(t, currCase.want, werror.IsRetryable(currCase.err))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package werror
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type httpAttemptsContextKey struct{}
+
+// ContextWithHTTPAttempts returns a context that counts the round trips made using the round tripper returned by
+// NewRoundTripper. Callers that retry or follow redirects should create the context once per logical call so that the
+// errors returned by the round tripper store the attempt number.
+func ContextWithHTTPAttempts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, httpAttemptsContextKey{}, new(int32))
+}
+
+// NewRoundTripper returns an http.RoundTripper that wraps the errors returned by base. If base is nil,
+// http.DefaultTransport is used.
+//
+// The returned errors include the wparams parameters stored in the request context and store the request method and
+// host, the time elapsed during the round trip and whether the request was canceled or timed out as safe parameters,
+// and the request URL and query as unsafe parameters. If the request context was created using ContextWithHTTPAttempts,
+// the attempt number is stored as a safe parameter. The original error is stored as the cause so that it remains
+// available to errors.Is and errors.As. Note that http.Client wraps the errors returned by its transport in a
+// *url.Error, so errors.As should be used to retrieve the werror from the errors returned by the client.
+func NewRoundTripper(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &roundTripper{base: base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var attempt int32
+	if attempts, ok := ctx.Value(httpAttemptsContextKey{}).(*int32); ok {
+		attempt = atomic.AddInt32(attempts, 1)
+	}
+	start := time.Now()
+	resp, err := rt.base.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	params := []Param{
+		SafeParam("method", req.Method),
+		SafeParam("elapsed", time.Since(start)),
+		SafeParam("canceled", errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled)),
+		SafeParam("timedOut", isTimeout(err) || errors.Is(ctx.Err(), context.DeadlineExceeded)),
+	}
+	if attempt > 0 {
+		params = append(params, SafeParam("attempt", int(attempt)))
+	}
+	if req.URL != nil {
+		params = append(params,
+			SafeParam("host", req.URL.Host),
+			UnsafeParam("url", req.URL.String()),
+		)
+		if req.URL.RawQuery != "" {
+			params = append(params, UnsafeParam("query", req.URL.RawQuery))
+		}
+	}
+	return resp, WrapWithContextParams(ctx, err, "http round trip failed", params...)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
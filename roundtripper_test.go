@@ -0,0 +1,85 @@
+package werror_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewRoundTripper(t *testing.T) {
+	sentinel := &url.Error{Op: "Post", URL: "http://example.com/users", Err: errors.New("connection refused")}
+	rt := werror.NewRoundTripper(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, sentinel
+	}))
+	ctx := wparams.ContextWithSafeParam(werror.ContextWithHTTPAttempts(context.Background()), "requestId", "abc")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://example.com/users?email=secret", nil)
+	require.NoError(t, err)
+
+	_, err = rt.RoundTrip(req)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, sentinel))
+	var urlErr *url.Error
+	assert.True(t, errors.As(err, &urlErr))
+
+	safe, unsafe := werror.ParamsFromError(err)
+	assert.IsType(t, time.Duration(0), safe["elapsed"])
+	delete(safe, "elapsed")
+	assert.Equal(t, map[string]interface{}{
+		"method":    http.MethodPost,
+		"host":      "example.com",
+		"attempt":   1,
+		"canceled":  false,
+		"timedOut":  false,
+		"requestId": "abc",
+	}, safe)
+	assert.Equal(t, map[string]interface{}{
+		"url":   "http://example.com/users?email=secret",
+		"query": "email=secret",
+	}, unsafe)
+
+	// second attempt with the same context
+	_, err = rt.RoundTrip(req)
+	attempt, _ := werror.ParamFromError(err, "attempt")
+	assert.Equal(t, 2, attempt)
+}
+
+func TestNewRoundTripper_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, reqErr)
+
+	// http.Client wraps transport errors in a *url.Error, so the werror is retrieved using errors.As
+	client := &http.Client{Transport: werror.NewRoundTripper(nil)}
+	_, clientErr := client.Do(req)
+	require.Error(t, clientErr)
+	assert.True(t, errors.Is(clientErr, context.DeadlineExceeded))
+	var err werror.Werror
+	require.True(t, errors.As(clientErr, &err))
+	timedOut, _ := werror.ParamFromError(err, "timedOut")
+	assert.Equal(t, true, timedOut)
+	canceled, _ := werror.ParamFromError(err, "canceled")
+	assert.Equal(t, false, canceled)
+	_, ok := werror.ParamFromError(err, "attempt")
+	assert.False(t, ok)
+}
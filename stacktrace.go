@@ -2,7 +2,11 @@ package werror
 
 import (
 	"fmt"
+	"maps"
 	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/palantir/witchcraft-go-error/internal/errors"
 )
@@ -31,10 +35,67 @@ func NewStackTraceWithSkip(skip int) StackTrace {
 	// Changing this back to "3" by default. Most callers have only a single level of indirection. For newWerror
 	// specifically, which is always called indirectly, we now call this with skip of "1".
 	n := runtime.Callers(skip+3, pcs[:])
-	var st stack = pcs[0:n]
+	return internStack(pcs[:n])
+}
+
+// maxInternedStacks bounds the number of distinct stacks that are interned so that programs that create errors from an
+// unbounded number of call sites (for example, using deep recursion) do not grow the table indefinitely.
+const maxInternedStacks = 4096
+
+var (
+	internedStacksMu sync.Mutex
+	// internedStacks stores an immutable map of the stacks created so far keyed by the hash of their program counters.
+	// Errors are typically created from a small number of call sites, so sharing the stack of every error created from
+	// the same call site avoids allocating it again. Stacks are never modified once created. The map is replaced
+	// whenever a stack is interned so that looking up a stack when creating an error only requires an atomic load.
+	internedStacks atomic.Pointer[map[uint64]*stack]
+)
+
+// internStack returns a stack with the provided program counters. The returned stack does not reference pcs: it is
+// either a previously interned stack with the same program counters or a right-sized copy of pcs.
+func internStack(pcs []uintptr) *stack {
+	h := hashPCs(pcs)
+	var interned *stack
+	current := internedStacks.Load()
+	if current != nil {
+		interned = (*current)[h]
+	}
+	if interned != nil && slices.Equal(*interned, pcs) {
+		return interned
+	}
+	st := stack(slices.Clone(pcs))
+	if interned == nil {
+		internedStacksMu.Lock()
+		current = internedStacks.Load()
+		if current == nil || ((*current)[h] == nil && len(*current) < maxInternedStacks) {
+			// copying the map is linear in the number of interned stacks, but stacks are only interned once per call
+			// site and the number of interned stacks is bounded
+			updated := make(map[uint64]*stack, 1)
+			if current != nil {
+				updated = maps.Clone(*current)
+			}
+			updated[h] = &st
+			internedStacks.Store(&updated)
+		}
+		internedStacksMu.Unlock()
+	}
 	return &st
 }
 
+// hashPCs returns the FNV-1a hash of the provided program counters.
+func hashPCs(pcs []uintptr) uint64 {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+	h := uint64(offset)
+	for _, pc := range pcs {
+		h ^= uint64(pc)
+		h *= prime
+	}
+	return h
+}
+
 // stack represents a stack of program counters.
 type stack []uintptr
 
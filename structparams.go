@@ -0,0 +1,160 @@
+package werror
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// StructParams returns a Param that stores the fields of the provided struct (or pointer to struct) as params based on
+// their "werror" struct tags:
+//
+//	type GetUserRequest struct {
+//		UserID   string  `werror:"userId,safe"`
+//		Email    string  `werror:"email,unsafe"`
+//		Password string  `werror:"-"`
+//		Owner    Account `werror:"owner"`
+//		Paging           // embedded structs are flattened
+//	}
+//
+// The tag consists of the param key followed by "safe" or "unsafe". Fields whose tag omits the safety are stored as
+// unsafe params, except for fields whose type is a struct (or pointer to struct) that has fields with "werror" tags:
+// the fields of such structs are stored using the key followed by a "." as a prefix (for example, "owner.id"). Structs
+// without "werror" tags, such as time.Time, are stored as a single value. Untagged fields and fields tagged with "-"
+// are ignored, except for untagged embedded structs, whose fields are stored without a prefix. A field whose value is a
+// nil pointer is omitted.
+//
+// The reflection plan for each struct type is computed once and cached, so this function is cheap enough to use on
+// hot paths. If v is not a struct or a non-nil pointer to a struct, the returned Param does nothing.
+func StructParams(v interface{}) Param {
+	return param(func(z *werror) {
+		val := reflect.ValueOf(v)
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			return
+		}
+		for _, field := range structPlanFor(val.Type()) {
+			fieldVal, ok := field.valueFrom(val)
+			if !ok {
+				continue
+			}
+			z.setParam(field.key, field.safe, fieldVal.Interface())
+		}
+	})
+}
+
+// structPlans caches the []structPlanField for each struct type.
+var structPlans sync.Map
+
+type structPlanField struct {
+	key   string
+	safe  bool
+	index []int
+}
+
+// valueFrom returns the value of the field in the provided struct, dereferencing any pointers along the way. Returns
+// false if the field is reached through a nil pointer, is itself a nil pointer or cannot be accessed.
+func (f structPlanField) valueFrom(val reflect.Value) (reflect.Value, bool) {
+	for _, i := range f.index {
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}, false
+			}
+			val = val.Elem()
+		}
+		val = val.Field(i)
+	}
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.Value{}, false
+		}
+		val = val.Elem()
+	}
+	return val, val.CanInterface()
+}
+
+func structPlanFor(t reflect.Type) []structPlanField {
+	if plan, ok := structPlans.Load(t); ok {
+		return plan.([]structPlanField)
+	}
+	plan, _ := structPlans.LoadOrStore(t, buildStructPlan(t, "", nil, map[reflect.Type]bool{t: true}))
+	return plan.([]structPlanField)
+}
+
+// buildStructPlan returns the fields of the provided struct type. The visiting map contains the struct types on the
+// current path and is used to stop the recursion for recursive types.
+func buildStructPlan(t reflect.Type, prefix string, index []int, visiting map[reflect.Type]bool) []structPlanField {
+	var fields []structPlanField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("werror")
+		if tag == "-" || (!tagged && !sf.Anonymous) {
+			continue
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		key, option := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			key, option = tag[:comma], tag[comma+1:]
+		}
+		if option == "" {
+			if structType, ok := structTypeOf(sf.Type); ok && (!tagged || hasWerrorTags(structType, map[reflect.Type]bool{})) {
+				if visiting[structType] {
+					continue
+				}
+				nestedPrefix := prefix
+				if key != "" {
+					nestedPrefix = prefix + key + "."
+				}
+				visiting[structType] = true
+				fields = append(fields, buildStructPlan(structType, nestedPrefix, fieldIndex, visiting)...)
+				delete(visiting, structType)
+				continue
+			}
+		}
+		if !tagged {
+			// untagged embedded fields that are not structs are ignored
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if key == "" {
+			key = sf.Name
+		}
+		fields = append(fields, structPlanField{
+			key:   prefix + key,
+			safe:  option == "safe",
+			index: fieldIndex,
+		})
+	}
+	return fields
+}
+
+// hasWerrorTags returns true if the provided struct type has fields with "werror" tags, including the fields of its
+// untagged embedded structs. The visited map is used to stop the recursion for recursive types.
+func hasWerrorTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if _, tagged := sf.Tag.Lookup("werror"); tagged {
+			return true
+		}
+		if structType, ok := structTypeOf(sf.Type); ok && sf.Anonymous && !visited[structType] && hasWerrorTags(structType, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// structTypeOf returns the struct type of t if t is a struct or a pointer to a struct.
+func structTypeOf(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
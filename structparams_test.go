@@ -0,0 +1,141 @@
+package werror_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+)
+
+type structParamsAccount struct {
+	ID    string `werror:"id,safe"`
+	Email string `werror:"email"`
+}
+
+type structParamsPaging struct {
+	PageSize  int    `werror:"pageSize,safe"`
+	PageToken string `werror:"pageToken,unsafe"`
+}
+
+type structParamsRequest struct {
+	UserID   string               `werror:"userId,safe"`
+	Email    string               `werror:"email,unsafe"`
+	Password string               `werror:"-"`
+	Comment  string               // untagged fields are ignored
+	Owner    structParamsAccount  `werror:"owner"`
+	Admin    *structParamsAccount `werror:"admin"`
+	Limit    *int                 `werror:"limit,safe"`
+	Next     *structParamsRequest `werror:"next"`
+	structParamsPaging
+}
+
+type structParamsEvent struct {
+	// structs without werror tags are stored as a single value
+	CreatedAt time.Time           `werror:"createdAt"`
+	DeletedAt *time.Time          `werror:"deletedAt,safe"`
+	Owner     structParamsAccount `werror:"owner"`
+}
+
+func TestStructParams(t *testing.T) {
+	limit := 10
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deletedAt := createdAt.Add(time.Hour)
+	for _, currCase := range []struct {
+		name       string
+		v          interface{}
+		wantSafe   map[string]interface{}
+		wantUnsafe map[string]interface{}
+	}{
+		{
+			name: "struct with nested, pointer and embedded fields",
+			v: structParamsRequest{
+				UserID:             "user-1",
+				Email:              "user@example.com",
+				Password:           "hunter2",
+				Comment:            "comment",
+				Owner:              structParamsAccount{ID: "owner-1", Email: "owner@example.com"},
+				Admin:              &structParamsAccount{ID: "admin-1"},
+				Limit:              &limit,
+				structParamsPaging: structParamsPaging{PageSize: 50, PageToken: "token"},
+			},
+			wantSafe: map[string]interface{}{
+				"userId":   "user-1",
+				"owner.id": "owner-1",
+				"admin.id": "admin-1",
+				"limit":    10,
+				"pageSize": 50,
+			},
+			wantUnsafe: map[string]interface{}{
+				"email":       "user@example.com",
+				"owner.email": "owner@example.com",
+				"admin.email": "",
+				"pageToken":   "token",
+			},
+		},
+		{
+			name: "pointer to struct with nil pointer fields",
+			v:    &structParamsRequest{UserID: "user-1"},
+			wantSafe: map[string]interface{}{
+				"userId":   "user-1",
+				"owner.id": "",
+				"pageSize": 0,
+			},
+			wantUnsafe: map[string]interface{}{
+				"email":       "",
+				"owner.email": "",
+				"pageToken":   "",
+			},
+		},
+		{
+			name: "struct fields without werror tags",
+			v: structParamsEvent{
+				CreatedAt: createdAt,
+				DeletedAt: &deletedAt,
+				Owner:     structParamsAccount{ID: "owner-1"},
+			},
+			wantSafe: map[string]interface{}{
+				"deletedAt": deletedAt,
+				"owner.id":  "owner-1",
+			},
+			wantUnsafe: map[string]interface{}{
+				"createdAt":   createdAt,
+				"owner.email": "",
+			},
+		},
+		{
+			name:       "nil pointer",
+			v:          (*structParamsRequest)(nil),
+			wantSafe:   map[string]interface{}{},
+			wantUnsafe: map[string]interface{}{},
+		},
+		{
+			name:       "non-struct",
+			v:          "value",
+			wantSafe:   map[string]interface{}{},
+			wantUnsafe: map[string]interface{}{},
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			err := werror.ErrorWithContextParams(context.Background(), "error", werror.StructParams(currCase.v))
+			safe, unsafe := werror.ParamsFromError(err)
+			assert.Equal(t, currCase.wantSafe, safe)
+			assert.Equal(t, currCase.wantUnsafe, unsafe)
+		})
+	}
+}
+
+func BenchmarkStructParams(b *testing.B) {
+	req := structParamsRequest{
+		UserID: "user-1",
+		Email:  "user@example.com",
+		Owner:  structParamsAccount{ID: "owner-1"},
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = werror.ErrorWithContextParams(ctx, "error", werror.StructParams(req))
+	}
+}
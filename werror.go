@@ -4,6 +4,9 @@ package werror
 import (
 	"context"
 	"fmt"
+	"maps"
+	"reflect"
+	"sync/atomic"
 
 	wparams "github.com/palantir/witchcraft-go-params"
 )
@@ -18,7 +21,8 @@ func Error(msg string, params ...Param) error {
 }
 
 // ErrorWithContextParams returns a new error with the provided message and parameters. The returned error also includes any
-// wparams parameters that are stored in the context.
+// wparams parameters that are stored in the context and the params returned by the registered context extractors (see
+// RegisterContextExtractor).
 //
 // The message should not contain any formatted parameters -- instead, use the SafeParam* or UnsafeParam* functions
 // to create error parameters.
@@ -30,13 +34,7 @@ func Error(msg string, params ...Param) error {
 //		return werror.ErrorWithContextParams(ctx, "configuration is missing password")
 //	}
 func ErrorWithContextParams(ctx context.Context, msg string, params ...Param) error {
-	safe, unsafe := wparams.SafeAndUnsafeParamsFromContext(ctx)
-	fullParams := []Param{
-		SafeParams(safe),
-		UnsafeParams(unsafe),
-	}
-	fullParams = append(fullParams, params...)
-	return newWerror(msg, nil, fullParams...)
+	return newWerrorWithContext(ctx, msg, nil, params...)
 }
 
 // Wrap is identical to calling WrapWithContextParams with a context that does not have any wparams parameters.
@@ -50,7 +48,8 @@ func Wrap(err error, msg string, params ...Param) error {
 }
 
 // WrapWithContextParams returns a new error with the provided message and stores the provided error as its cause.
-// The returned error also includes any wparams parameters that are stored in the context.
+// The returned error also includes any wparams parameters that are stored in the context and the params returned by the
+// registered context extractors (see RegisterContextExtractor).
 //
 // The message should not contain any formatted parameters -- instead use the SafeParam* or UnsafeParam* functions
 // to create error parameters.
@@ -65,18 +64,101 @@ func WrapWithContextParams(ctx context.Context, err error, msg string, params ..
 	if err == nil {
 		return nil
 	}
+	return newWerrorWithContext(ctx, msg, err, params...)
+}
+
+// applyContextParams stores the params returned by the registered context extractors and the wparams parameters stored
+// in the provided context on z, in that order, so that the wparams parameters take precedence. Nothing is allocated if
+// the context does not provide any params.
+func applyContextParams(ctx context.Context, z *werror) {
 	safe, unsafe := wparams.SafeAndUnsafeParamsFromContext(ctx)
-	fullParams := []Param{
-		SafeParams(safe),
-		UnsafeParams(unsafe),
+	if len(safe) == 0 && len(unsafe) == 0 && !hasExtractors() {
+		return
+	}
+	values := make(contextValuesParam, len(safe)+len(unsafe))
+	extractParams(ctx, values)
+	for k, v := range safe {
+		values[k] = paramValue{safe: true, value: v}
+	}
+	for k, v := range unsafe {
+		values[k] = paramValue{safe: false, value: v}
+	}
+	values.apply(z)
+}
+
+// contextValuesParam stores the params extracted from a context. Since the same context is typically used to create
+// every level of an error chain, params that are already stored deeper in the chain with the same safety and an equal
+// value are not stored again: they would not change the params of the chain, since the deepest value wins.
+type contextValuesParam map[string]paramValue
+
+func (p contextValuesParam) apply(z *werror) {
+	if len(p) == 0 {
+		return
+	}
+	causeSafe, causeUnsafe := p.valuesInChain(z.cause)
+	for k, v := range p {
+		existing := causeUnsafe
+		if v.safe {
+			existing = causeSafe
+		}
+		if causeValue, ok := existing[k]; ok && reflect.DeepEqual(causeValue, v.value) {
+			continue
+		}
+		z.setParam(k, v.safe, v.value)
 	}
-	fullParams = append(fullParams, params...)
-	return newWerror(msg, err, fullParams...)
+}
+
+// valuesInChain returns the deepest values in the chain of err for the keys of p, as they would be returned by
+// ParamsFromError. Only the params stored at each level are inspected so that the cost is linear in the depth of the
+// chain.
+func (p contextValuesParam) valuesInChain(err error) (safe map[string]interface{}, unsafe map[string]interface{}) {
+	record := func(k string, v interface{}, isSafe bool) {
+		if isSafe {
+			if safe == nil {
+				safe = make(map[string]interface{})
+			}
+			safe[k] = v
+		} else {
+			if unsafe == nil {
+				unsafe = make(map[string]interface{})
+			}
+			unsafe[k] = v
+		}
+	}
+	for currErr := err; currErr != nil; {
+		switch e := currErr.(type) {
+		case *werror:
+			for k := range p {
+				if v, ok := e.levelParam(k); ok {
+					record(k, v.value, v.safe)
+				}
+			}
+		case wparams.ParamStorer:
+			levelSafe, levelUnsafe := e.SafeParams(), e.UnsafeParams()
+			for k := range p {
+				if v, ok := levelSafe[k]; ok {
+					record(k, v, true)
+				}
+				if v, ok := levelUnsafe[k]; ok {
+					record(k, v, false)
+				}
+			}
+		}
+		causer, ok := currErr.(Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
+	}
+	return safe, unsafe
 }
 
 // Convert err to werror error.
 //
-// If err is not a werror-based error, then a new werror error is created using the message from err.
+// If err is not a werror-based error, then a new werror error is created using the message from err. The parameters
+// extracted from err by the registered adapters (see RegisterAdapter) are stored on the new error. They are returned
+// by SafeParams, UnsafeParams and ParamsFromError and are included in GenerateErrorString, but they are not printed when
+// the new error is formatted using the verbs of the fmt package, so that it is formatted like err.
 // Otherwise, returns unchanged err.
 //
 // Example:
@@ -93,7 +175,7 @@ func Convert(err error) error {
 	case Werror:
 		return err
 	default:
-		return newWerror("", err)
+		return newWerror("", err, adaptedParams(err)...)
 	}
 }
 
@@ -125,14 +207,20 @@ func RootCause(err error) error {
 // different values for the same keys, the values for the most specific (deepest) error will be the ones in the returned
 // maps.
 func ParamsFromError(err error) (safeParams map[string]interface{}, unsafeParams map[string]interface{}) {
+	if we, ok := err.(*werror); ok {
+		merged := we.mergedParams()
+		return maps.Clone(merged.safe), maps.Clone(merged.unsafe)
+	}
 	safeParams = make(map[string]interface{})
 	unsafeParams = make(map[string]interface{})
 	if err != nil {
 		visitErrorParams(err, func(k string, v interface{}, safe bool) {
 			if safe {
 				safeParams[k] = v
+				delete(unsafeParams, k)
 			} else {
 				unsafeParams[k] = v
+				delete(safeParams, k)
 			}
 		})
 	}
@@ -143,31 +231,53 @@ func ParamsFromError(err error) (safeParams map[string]interface{}, unsafeParams
 // parameters of the provided error and all of its causes. If the error and its causes contain multiple values for the
 // same key, the most specific (deepest) value will be returned.
 func ParamFromError(err error, key string) (value interface{}, safe bool) {
-	visitErrorParams(err, func(k string, v interface{}, s bool) {
-		if k == key {
-			value = v
-			safe = s
+	for currErr := err; currErr != nil; {
+		if we, ok := currErr.(*werror); ok {
+			// the merged params of a werror already include the deepest values of all of its causes
+			merged := we.mergedParams()
+			if v, ok := merged.safe[key]; ok {
+				return v, true
+			}
+			if v, ok := merged.unsafe[key]; ok {
+				return v, false
+			}
+			return value, safe
+		}
+		if ps, ok := currErr.(wparams.ParamStorer); ok {
+			if v, ok := ps.SafeParams()[key]; ok {
+				value, safe = v, true
+			}
+			if v, ok := ps.UnsafeParams()[key]; ok {
+				value, safe = v, false
+			}
 		}
-	})
+		causer, ok := currErr.(Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
+	}
 	return value, safe
 }
 
 // visitErrorParams calls the provided visitor function on all of the parameters stored in the provided error and any of
 // its causes. The function is invoked on all of the parameters stored in the provided error, then all of the parameters
-// in the cause of the provided error, and so on. There are no guarantees made about the order in which the parameters
-// will be called for a given error.
+// in the cause of the provided error, and so on. Since the params of a werror include the params of its causes, the
+// traversal stops at the first werror in the chain. There are no guarantees made about the order in which the
+// parameters will be called for a given error.
 func visitErrorParams(err error, visitor func(k string, v interface{}, safe bool)) {
-	allErrs := []error{err}
-	for currErr := err; ; {
-		causer, ok := currErr.(Causer)
-		if !ok || causer.Cause() == nil {
-			// current error does not have a cause
-			break
+	for currErr := err; currErr != nil; {
+		if we, ok := currErr.(*werror); ok {
+			// the merged params of a werror already include the deepest values of all of its causes
+			merged := we.mergedParams()
+			for k, v := range merged.safe {
+				visitor(k, v, true)
+			}
+			for k, v := range merged.unsafe {
+				visitor(k, v, false)
+			}
+			return
 		}
-		allErrs = append(allErrs, causer.Cause())
-		currErr = causer.Cause()
-	}
-	for _, currErr := range allErrs {
 		if ps, ok := currErr.(wparams.ParamStorer); ok {
 			for k, v := range ps.SafeParams() {
 				visitor(k, v, true)
@@ -176,6 +286,11 @@ func visitErrorParams(err error, visitor func(k string, v interface{}, safe bool
 				visitor(k, v, false)
 			}
 		}
+		causer, ok := currErr.(Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
 	}
 }
 
@@ -196,12 +311,41 @@ type werror struct {
 	message string
 	cause   error
 	stack   StackTrace
-	params  map[string]paramValue
+	// params are the params stored at the level of this error, in the order in which they were first set.
+	params     []storedParam
+	errorType  ErrorType
+	instanceID string
+	retryable  retryHint
+	// definitionParams stores the params value provided to Definition.New or Definition.Wrap.
+	definitionParams interface{}
+	// merged caches the result of mergedParams.
+	merged atomic.Pointer[mergedParams]
 }
 
 type paramValue struct {
-	safe  bool
-	value interface{}
+	safe bool
+	// adapted is true if the param was extracted from the cause of the error by an adapter (see RegisterAdapter).
+	// Adapted params are not formatted by the error's Format method, so that formatting a converted error prints the
+	// same output as formatting the error it converts.
+	adapted bool
+	value   interface{}
+}
+
+type storedParam struct {
+	key string
+	paramValue
+}
+
+// apply stores the param on the provided error. SafeParam and UnsafeParam return a storedParam so that creating a
+// single param does not allocate a map and a closure.
+func (p storedParam) apply(z *werror) {
+	z.setParam(p.key, p.safe, p.value)
+}
+
+// mergedParams are the params of an error and its causes.
+type mergedParams struct {
+	safe   map[string]interface{}
+	unsafe map[string]interface{}
 }
 
 // Causer interface is compatible with the interface used by pkg/errors.
@@ -210,15 +354,30 @@ type Causer interface {
 }
 
 func newWerror(message string, cause error, params ...Param) error {
+	return newWerrorWithStack(nil, message, cause, NewStackTraceWithSkip(1), params...)
+}
+
+// newWerrorWithContext is like newWerror, but the returned error also stores the params provided by ctx (see
+// applyContextParams) before the provided params.
+func newWerrorWithContext(ctx context.Context, message string, cause error, params ...Param) error {
+	return newWerrorWithStack(ctx, message, cause, NewStackTraceWithSkip(1), params...)
+}
+
+// newWerrorWithStack returns a new error with the provided stack. If ctx is non-nil, the params it provides are
+// stored before the provided params and it is provided to the registered observers.
+func newWerrorWithStack(ctx context.Context, message string, cause error, stack StackTrace, params ...Param) error {
 	we := &werror{
 		message: message,
 		cause:   cause,
-		stack:   NewStackTraceWithSkip(1),
-		params:  make(map[string]paramValue),
+		stack:   stack,
+	}
+	if ctx != nil {
+		applyContextParams(ctx, we)
 	}
 	for _, p := range params {
 		p.apply(we)
 	}
+	notifyObservers(we, ctx)
 	return we
 }
 
@@ -257,40 +416,117 @@ func (e *werror) Message() string {
 // SafeParams returns params from this error and any underlying causes. If the error and its causes
 // contain multiple values for the same key, the most specific (deepest) value will be returned.
 func (e *werror) SafeParams() map[string]interface{} {
-	safe, _ := ParamsFromError(e.cause)
-	for k, v := range e.params {
-		if v.safe {
-			if _, exists := safe[k]; !exists {
-				safe[k] = v.value
-			}
-		}
-	}
-	return safe
+	return maps.Clone(e.mergedParams().safe)
 }
 
 // UnsafeParams returns params from this error and any underlying causes. If the error and its causes
 // contain multiple values for the same key, the most specific (deepest) value will be returned.
 func (e *werror) UnsafeParams() map[string]interface{} {
-	_, unsafe := ParamsFromError(e.cause)
-	for k, v := range e.params {
-		if !v.safe {
-			if _, exists := unsafe[k]; !exists {
-				unsafe[k] = v.value
-			}
+	return maps.Clone(e.mergedParams().unsafe)
+}
+
+// setParam stores the provided param at the level of this error, replacing any param with the same key that is
+// already stored at this level. It must only be called while the error is being created.
+func (e *werror) setParam(key string, safe bool, value interface{}) {
+	e.setParamValue(key, paramValue{safe: safe, value: value})
+}
+
+func (e *werror) setParamValue(key string, value paramValue) {
+	for i := range e.params {
+		if e.params[i].key == key {
+			e.params[i] = storedParam{key: key, paramValue: value}
+			return
 		}
 	}
-	return unsafe
+	e.params = append(e.params, storedParam{key: key, paramValue: value})
+}
+
+// levelParam returns the param with the provided key that is stored at the level of this error.
+func (e *werror) levelParam(key string) (paramValue, bool) {
+	for _, p := range e.params {
+		if p.key == key {
+			return p.paramValue, true
+		}
+	}
+	return paramValue{}, false
+}
+
+// mergedParams returns the params of this error and its causes, where the deepest value of each key wins. The result is
+// computed on first use and cached, and it reuses the cached result of the cause, so accessing the params of every level
+// of a chain is linear in the depth of the chain. The returned maps must not be modified.
+func (e *werror) mergedParams() *mergedParams {
+	if merged := e.merged.Load(); merged != nil {
+		return merged
+	}
+	var causeMerged *mergedParams
+	if cause, ok := e.cause.(*werror); ok {
+		causeMerged = cause.mergedParams()
+	} else {
+		safe, unsafe := ParamsFromError(e.cause)
+		causeMerged = &mergedParams{safe: safe, unsafe: unsafe}
+	}
+	merged := causeMerged
+	for _, p := range e.params {
+		// the deepest value of a key wins regardless of its safety, so a key stored by a cause with a different safety
+		// must not be stored in the other map
+		if _, ok := causeMerged.safe[p.key]; ok {
+			continue
+		}
+		if _, ok := causeMerged.unsafe[p.key]; ok {
+			continue
+		}
+		if merged == causeMerged {
+			// copy the cause's maps only when this level adds a param
+			merged = &mergedParams{safe: maps.Clone(causeMerged.safe), unsafe: maps.Clone(causeMerged.unsafe)}
+		}
+		if p.safe {
+			merged.safe[p.key] = p.value
+		} else {
+			merged.unsafe[p.key] = p.value
+		}
+	}
+	e.merged.Store(merged)
+	return merged
 }
 
 // Format formats the error using the provided format state. Delegates to stored error.
+//
+// Like GenerateErrorString, annotation levels (see WithParams) whose cause is a werror are not formatted separately: their
+// safe params are formatted along with the params of the next level that is not an annotation. Since the deepest value
+// of a key wins, the params of an annotation are omitted if the annotated level, a deeper level or a deeper annotation
+// also stores them.
 func (e *werror) Format(state fmt.State, verb rune) {
+	var annotations []*werror
+	level := e
+	for {
+		cause, ok := level.cause.(*werror)
+		if !ok || !isAnnotation(level) {
+			break
+		}
+		annotations = append(annotations, level)
+		level = cause
+	}
 	safe := make(map[string]interface{})
-	for k, v := range e.params {
-		if v.safe {
-			safe[k] = v.value
+	for _, p := range level.params {
+		if p.safe && !p.adapted {
+			safe[p.key] = p.value
+		}
+	}
+	if len(annotations) > 0 {
+		merged := level.mergedParams()
+		seen := make(map[string]struct{})
+		for i := len(annotations) - 1; i >= 0; i-- {
+			for _, p := range annotations[i].params {
+				_, inSafe := merged.safe[p.key]
+				_, inUnsafe := merged.unsafe[p.key]
+				if _, ok := seen[p.key]; !ok && p.safe && !inSafe && !inUnsafe {
+					safe[p.key] = p.value
+				}
+				seen[p.key] = struct{}{}
+			}
 		}
 	}
-	Format(e, safe, state, verb)
+	Format(level, safe, state, verb)
 }
 
 // Format formats a Werror using the provided format state. This is a utility method that can
@@ -299,7 +535,7 @@ func (e *werror) Format(state fmt.State, verb rune) {
 func Format(err Werror, safeParams map[string]interface{}, state fmt.State, verb rune) {
 	if verb == 'v' && state.Flag('+') {
 		// Multi-line extra verbose format starts with cause first followed up by current error metadata.
-		formatCause(err, state, verb)
+		formatCause(err, safeParams, state, verb)
 		formatMessage(err, state, verb)
 		formatParameters(err, safeParams, state, verb)
 		formatStack(err, state, verb)
@@ -307,7 +543,7 @@ func Format(err Werror, safeParams map[string]interface{}, state fmt.State, verb
 		formatMessage(err, state, verb)
 		formatParameters(err, safeParams, state, verb)
 		formatStack(err, state, verb)
-		formatCause(err, state, verb)
+		formatCause(err, safeParams, state, verb)
 	}
 }
 
@@ -345,12 +581,13 @@ func formatStack(err Werror, state fmt.State, verb rune) {
 	err.StackTrace().Format(state, verb)
 }
 
-func formatCause(err Werror, state fmt.State, verb rune) {
+func formatCause(err Werror, safeParams map[string]interface{}, state fmt.State, verb rune) {
 	if err.Cause() == nil {
 		return
 	}
 	var prefix string
-	if err.Message() != "" || (verb == 'v' && len(err.SafeParams()) > 0) {
+	// only add a separator if this level printed something: the safe params of the causes are printed by the causes
+	if err.Message() != "" || (verb == 'v' && len(safeParams) > 0) {
 		prefix = ": "
 	}
 	switch verb {
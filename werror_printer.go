@@ -27,13 +27,28 @@ func GenerateErrorString(err error, outputEveryCallingStack bool) string {
 
 func generateWerrorString(err Werror, outputEveryCallingStack bool) string {
 	var buffer bytes.Buffer
-	writeMessage(err, &buffer)
-	writeParams(err, &buffer)
-	writeCause(err, &buffer, outputEveryCallingStack)
-	writeStack(err, &buffer, outputEveryCallingStack)
+	writeWerror(err, &buffer, outputEveryCallingStack, nil)
 	return buffer.String()
 }
 
+// writeWerror writes the provided error to the buffer. The provided annotationParams are the safe params of the
+// annotation levels (see WithParams) directly above err, which are written along with the params of err.
+func writeWerror(err Werror, buffer *bytes.Buffer, outputEveryCallingStack bool, annotationParams map[string]interface{}) {
+	if cause, ok := err.Cause().(Werror); ok && isAnnotation(err) {
+		// collapse the annotation into its cause
+		params := getSafeParamsAtCurrentLevel(err)
+		for k, v := range annotationParams {
+			params[k] = v
+		}
+		writeWerror(cause, buffer, outputEveryCallingStack, params)
+		return
+	}
+	writeMessage(err, buffer)
+	writeParams(err, buffer, annotationParams)
+	writeCause(err, buffer, outputEveryCallingStack)
+	writeStack(err, buffer, outputEveryCallingStack)
+}
+
 func writeMessage(err Werror, buffer *bytes.Buffer) {
 	if err.Message() == "" {
 		return
@@ -41,8 +56,11 @@ func writeMessage(err Werror, buffer *bytes.Buffer) {
 	buffer.WriteString(err.Message())
 }
 
-func writeParams(err Werror, buffer *bytes.Buffer) {
+func writeParams(err Werror, buffer *bytes.Buffer, annotationParams map[string]interface{}) {
 	safeParams := getSafeParamsAtCurrentLevel(err)
+	for k, v := range annotationParams {
+		safeParams[k] = v
+	}
 	var safeKeys []string
 	for k := range safeParams {
 		safeKeys = append(safeKeys, k)
@@ -71,6 +89,26 @@ func writeParams(err Werror, buffer *bytes.Buffer) {
 
 func getSafeParamsAtCurrentLevel(err Werror) map[string]interface{} {
 	safeParamsAtThisLevel := make(map[string]interface{}, 0)
+	if we, ok := err.(*werror); ok {
+		// only inspect the params stored at this level to avoid copying the params of the entire chain
+		var childSafeParams, childUnsafeParams map[string]interface{}
+		switch cause := we.cause.(type) {
+		case *werror:
+			merged := cause.mergedParams()
+			childSafeParams, childUnsafeParams = merged.safe, merged.unsafe
+		case Werror:
+			childSafeParams, childUnsafeParams = cause.SafeParams(), cause.UnsafeParams()
+		}
+		for _, p := range we.params {
+			// a param that is also stored deeper in the chain is overridden by the deeper value, even if it is unsafe
+			_, inChildSafe := childSafeParams[p.key]
+			_, inChildUnsafe := childUnsafeParams[p.key]
+			if p.safe && !inChildSafe && !inChildUnsafe {
+				safeParamsAtThisLevel[p.key] = p.value
+			}
+		}
+		return safeParamsAtThisLevel
+	}
 	childSafeParams := getChildSafeParams(err)
 	for k, v := range err.SafeParams() {
 		_, ok := childSafeParams[k]
@@ -94,12 +132,19 @@ func getChildSafeParams(err Werror) map[string]interface{} {
 }
 
 func writeCause(err Werror, buffer *bytes.Buffer, outputEveryCallingStack bool) {
+	if cause, ok := err.Cause().(Werror); ok {
+		writeWerror(cause, buffer, outputEveryCallingStack, nil)
+		return
+	}
 	if err.Cause() != nil {
 		buffer.WriteString(GenerateErrorString(err.Cause(), outputEveryCallingStack))
 	}
 }
 
 func writeStack(err Werror, buffer *bytes.Buffer, outputEveryCallingStack bool) {
+	if err.StackTrace() == nil {
+		return
+	}
 	if _, ok := err.Cause().(Werror); ok {
 		if !outputEveryCallingStack {
 			return
@@ -678,3 +678,119 @@ func TestGoErrorWrapping(t *testing.T) {
 	// "errors.Is" should return true because sentinelError is wrapped 2 levels deep
 	assert.True(t, errors.Is(doubleWrappedErr, sentinelError))
 }
+
+func TestParamsAreNotSharedBetweenCallers(t *testing.T) {
+	inner := werror.Error("inner", werror.SafeParam("key", "value"))
+	outer := werror.Wrap(inner, "outer")
+
+	safe := outer.(werror.Werror).SafeParams()
+	safe["key"] = "modified"
+	safe["added"] = "value"
+	safeFromError, _ := werror.ParamsFromError(inner)
+	safeFromError["key"] = "modified"
+
+	assert.Equal(t, map[string]interface{}{"key": "value"}, inner.(werror.Werror).SafeParams())
+	assert.Equal(t, map[string]interface{}{"key": "value"}, outer.(werror.Werror).SafeParams())
+	value, isSafe := werror.ParamFromError(outer, "key")
+	assert.Equal(t, "value", value)
+	assert.True(t, isSafe)
+}
+
+func TestParamsWithMixedSafetyAcrossLevels(t *testing.T) {
+	for _, currCase := range []struct {
+		name       string
+		err        error
+		wantValue  interface{}
+		wantIsSafe bool
+		wantSafe   map[string]interface{}
+		wantUnsafe map[string]interface{}
+	}{
+		{
+			name:       "outer unsafe overridden by inner safe",
+			err:        werror.Wrap(werror.Error("inner", werror.SafeParam("k", "innerSafe")), "outer", werror.UnsafeParam("k", "outerUnsafe")),
+			wantValue:  "innerSafe",
+			wantIsSafe: true,
+			wantSafe:   map[string]interface{}{"k": "innerSafe"},
+			wantUnsafe: map[string]interface{}{},
+		},
+		{
+			name:       "outer safe overridden by inner unsafe",
+			err:        werror.Wrap(werror.Error("inner", werror.UnsafeParam("k", "innerUnsafe")), "outer", werror.SafeParam("k", "outerSafe")),
+			wantValue:  "innerUnsafe",
+			wantIsSafe: false,
+			wantSafe:   map[string]interface{}{},
+			wantUnsafe: map[string]interface{}{"k": "innerUnsafe"},
+		},
+		{
+			name: "deepest of three levels wins",
+			err: werror.Wrap(
+				werror.Wrap(werror.Error("inner", werror.SafeParam("k", "innerSafe")), "middle", werror.UnsafeParam("k", "middleUnsafe")),
+				"outer", werror.SafeParam("k", "outerSafe"),
+			),
+			wantValue:  "innerSafe",
+			wantIsSafe: true,
+			wantSafe:   map[string]interface{}{"k": "innerSafe"},
+			wantUnsafe: map[string]interface{}{},
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			value, isSafe := werror.ParamFromError(currCase.err, "k")
+			assert.Equal(t, currCase.wantValue, value)
+			assert.Equal(t, currCase.wantIsSafe, isSafe)
+
+			safe, unsafe := werror.ParamsFromError(currCase.err)
+			assert.Equal(t, currCase.wantSafe, safe)
+			assert.Equal(t, currCase.wantUnsafe, unsafe)
+			assert.Equal(t, currCase.wantSafe, currCase.err.(werror.Werror).SafeParams())
+			assert.Equal(t, currCase.wantUnsafe, currCase.err.(werror.Werror).UnsafeParams())
+			assert.NotContains(t, werror.GenerateErrorString(currCase.err, false), "outerSafe")
+		})
+	}
+}
+
+func newDeepChain(depth int) error {
+	err := werror.Error("root", werror.SafeParam("level", 0), werror.UnsafeParam("unsafeLevel", 0))
+	for level := 1; level < depth; level++ {
+		err = werror.Wrap(err, "wrapped", werror.SafeParam(fmt.Sprintf("key%d", level), level))
+	}
+	return err
+}
+
+func BenchmarkParamsFromErrorDeepChain(b *testing.B) {
+	for _, depth := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("depth %d", depth), func(b *testing.B) {
+			err := newDeepChain(depth)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = werror.ParamsFromError(err)
+			}
+		})
+	}
+}
+
+func BenchmarkParamFromErrorDeepChain(b *testing.B) {
+	for _, depth := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("depth %d", depth), func(b *testing.B) {
+			err := newDeepChain(depth)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = werror.ParamFromError(err, "level")
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateErrorStringDeepChain(b *testing.B) {
+	for _, depth := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("depth %d", depth), func(b *testing.B) {
+			err := newDeepChain(depth)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = werror.GenerateErrorString(err, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+module github.com/palantir/witchcraft-go-error/werrorgrpc
+
+go 1.21
+
+require (
+	github.com/palantir/witchcraft-go-error v1.34.0
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157
+	google.golang.org/grpc v1.65.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/palantir/witchcraft-go-params v1.32.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/protobuf v1.34.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// This module uses APIs of github.com/palantir/witchcraft-go-error that have not been released yet, so it is built
+// against the code in this repository and is not tagged. Before tagging it, require the first release that contains
+// these APIs and remove this directive.
+replace github.com/palantir/witchcraft-go-error => ../
@@ -0,0 +1,82 @@
+package werrorgrpc
+
+import (
+	"context"
+	"io"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a server interceptor that converts errors returned by unary handlers to gRPC status
+// errors using ToStatus.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that converts errors returned by stream handlers to gRPC status
+// errors using ToStatus.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that converts gRPC status errors to werrors using FromStatus.
+// The returned errors store the full method name as a safe parameter.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return fromClientError(ctx, method, invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that converts gRPC status errors returned when creating and
+// using client streams to werrors using FromStatus. io.EOF is returned unchanged. The returned errors store the full
+// method name as a safe parameter.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, fromClientError(ctx, method, err)
+		}
+		return &clientStream{ClientStream: cs, ctx: ctx, method: method}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	method string
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return fromClientError(s.ctx, s.method, s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return fromClientError(s.ctx, s.method, s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return fromClientError(s.ctx, s.method, s.ClientStream.CloseSend())
+}
+
+func fromClientError(ctx context.Context, method string, err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	if st, ok := status.FromError(err); ok {
+		err = FromStatus(ctx, st)
+	}
+	return werror.WrapWithContextParams(ctx, err, "gRPC call failed", werror.SafeParam("method", method))
+}
@@ -0,0 +1,155 @@
+// Package werrorgrpc converts werrors to and from gRPC statuses and provides interceptors that perform the conversion
+// for gRPC servers and clients.
+package werrorgrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ErrorInfoDomain is the domain of the errdetails.ErrorInfo details created by ToStatus.
+	ErrorInfoDomain = "witchcraft-go-error"
+
+	errorCodeMetadataKey       = "errorCode"
+	errorInstanceIDMetadataKey = "errorInstanceId"
+	paramMetadataKeyPrefix     = "param."
+)
+
+var categoryCodes = map[werror.Category]codes.Code{
+	werror.CategoryPermissionDenied:      codes.PermissionDenied,
+	werror.CategoryInvalidArgument:       codes.InvalidArgument,
+	werror.CategoryNotFound:              codes.NotFound,
+	werror.CategoryConflict:              codes.Aborted,
+	werror.CategoryRequestEntityTooLarge: codes.ResourceExhausted,
+	werror.CategoryFailedPrecondition:    codes.FailedPrecondition,
+	werror.CategoryInternal:              codes.Internal,
+	werror.CategoryTimeout:               codes.DeadlineExceeded,
+	werror.CategoryCustomClient:          codes.InvalidArgument,
+	werror.CategoryCustomServer:          codes.Internal,
+}
+
+var codeTypes = map[codes.Code]werror.ErrorType{
+	codes.Canceled:           werror.DefaultInternal,
+	codes.Unknown:            werror.DefaultInternal,
+	codes.InvalidArgument:    werror.DefaultInvalidArgument,
+	codes.DeadlineExceeded:   werror.DefaultTimeout,
+	codes.NotFound:           werror.DefaultNotFound,
+	codes.AlreadyExists:      werror.DefaultConflict,
+	codes.PermissionDenied:   werror.DefaultPermissionDenied,
+	codes.ResourceExhausted:  werror.DefaultRequestEntityTooLarge,
+	codes.FailedPrecondition: werror.DefaultFailedPrecondition,
+	codes.Aborted:            werror.DefaultConflict,
+	codes.OutOfRange:         werror.DefaultInvalidArgument,
+	codes.Unimplemented:      werror.DefaultInternal,
+	codes.Internal:           werror.DefaultInternal,
+	codes.Unavailable:        werror.DefaultInternal,
+	codes.DataLoss:           werror.DefaultInternal,
+	codes.Unauthenticated:    werror.DefaultPermissionDenied,
+}
+
+// CodeForCategory returns the gRPC code for the provided category. Returns codes.Internal for unknown categories.
+func CodeForCategory(category werror.Category) codes.Code {
+	if code, ok := categoryCodes[category]; ok {
+		return code
+	}
+	return codes.Internal
+}
+
+// TypeForCode returns the default error type for the provided gRPC code. Returns werror.DefaultInternal for unknown
+// codes.
+func TypeForCode(code codes.Code) werror.ErrorType {
+	if errorType, ok := codeTypes[code]; ok {
+		return errorType
+	}
+	return werror.DefaultInternal
+}
+
+// ToStatus returns the gRPC status for the provided error, or nil if err is nil.
+//
+// The code of the status is determined by the category of the error. The message of the status is the error name,
+// and the error name, category, instance ID and safe parameters are stored in an errdetails.ErrorInfo detail. Safe
+// parameter values are converted to strings using fmt.Sprint. Unsafe parameters are never included.
+//
+// If the error does not declare a type but its chain contains a gRPC status, that status is returned unchanged.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	errorType, ok := werror.TypeFromError(err)
+	if !ok {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		errorType = werror.DefaultInternal
+	}
+	metadata := map[string]string{
+		errorCodeMetadataKey: string(errorType.Category()),
+	}
+	if instanceID := werror.InstanceIDFromError(err); instanceID != "" {
+		metadata[errorInstanceIDMetadataKey] = instanceID
+	}
+	safe, _ := werror.ParamsFromError(err)
+	for k, v := range safe {
+		metadata[paramMetadataKeyPrefix+k] = fmt.Sprint(v)
+	}
+	st := status.New(CodeForCategory(errorType.Category()), errorType.Name())
+	if withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   errorType.Name(),
+		Domain:   ErrorInfoDomain,
+		Metadata: metadata,
+	}); detailsErr == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// FromStatus returns a werror for the provided gRPC status, or nil if the status is nil or OK.
+//
+// If the status has an errdetails.ErrorInfo detail created by ToStatus, the returned error has the remote error name as
+// its message and the remote instance ID, stores the remote error name and code as the safe parameters
+// "remoteErrorName" and "remoteErrorCode" and stores the remote parameters as safe parameters. Otherwise, the returned
+// error stores the code as a safe parameter and stores the status message as an unsafe parameter since it may contain
+// arbitrary content.
+//
+// Like werror.FromHTTPResponse, the returned error does not declare a type, so its category is werror.CategoryInternal:
+// a service that returns the error to its own callers (for example, using UnaryServerInterceptor) must not respond with
+// the code of its dependency. Callers that want to propagate the remote error can inspect the remote error code and wrap
+// the error with an appropriate werror.Type.
+func FromStatus(ctx context.Context, st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != ErrorInfoDomain {
+			continue
+		}
+		params := []werror.Param{
+			werror.SafeParam("grpcCode", st.Code().String()),
+			werror.SafeParam("remoteErrorName", info.GetReason()),
+			werror.SafeParam("remoteErrorCode", info.GetMetadata()[errorCodeMetadataKey]),
+		}
+		safe := make(map[string]interface{})
+		for k, v := range info.GetMetadata() {
+			switch {
+			case k == errorInstanceIDMetadataKey:
+				params = append(params, werror.InstanceID(v))
+			case strings.HasPrefix(k, paramMetadataKeyPrefix):
+				safe[strings.TrimPrefix(k, paramMetadataKeyPrefix)] = v
+			}
+		}
+		params = append(params, werror.SafeParams(safe))
+		return werror.ErrorWithContextParams(ctx, info.GetReason(), params...)
+	}
+	return werror.ErrorWithContextParams(ctx, "gRPC error status",
+		werror.SafeParam("grpcCode", st.Code().String()),
+		werror.UnsafeParam("grpcMessage", st.Message()),
+	)
+}
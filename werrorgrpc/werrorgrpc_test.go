@@ -0,0 +1,181 @@
+package werrorgrpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-error/werrorgrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testNotFoundType = werror.MustErrorType(werror.CategoryNotFound, "Test:ServiceNotFound")
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	return s.err
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	err := werror.ErrorWithContextParams(context.Background(), "service not found",
+		werror.Type(testNotFoundType),
+		werror.InstanceID("instance-id"),
+		werror.SafeParam("service", "users"),
+		werror.SafeParam("attempt", 2),
+		werror.UnsafeParam("token", "secret"),
+	)
+	st := werrorgrpc.ToStatus(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "Test:ServiceNotFound", st.Message())
+
+	converted := werrorgrpc.FromStatus(context.Background(), st)
+	assert.EqualError(t, converted, "Test:ServiceNotFound")
+	_, ok := werror.TypeFromError(converted)
+	assert.False(t, ok)
+	assert.Equal(t, "instance-id", werror.InstanceIDFromError(converted))
+	safe, unsafe := werror.ParamsFromError(converted)
+	assert.Equal(t, map[string]interface{}{
+		"service":         "users",
+		"attempt":         "2",
+		"grpcCode":        "NotFound",
+		"remoteErrorName": "Test:ServiceNotFound",
+		"remoteErrorCode": "NOT_FOUND",
+	}, safe)
+	assert.Empty(t, unsafe)
+
+	// a service that returns the converted error responds with an internal error, but preserves the instance ID
+	rethrown := werrorgrpc.ToStatus(werror.WrapWithContextParams(context.Background(), converted, "lookup failed"))
+	assert.Equal(t, codes.Internal, rethrown.Code())
+	assert.Equal(t, "Default:Internal", rethrown.Message())
+}
+
+func TestToStatus(t *testing.T) {
+	for _, currCase := range []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{
+			name:     "untyped werror",
+			err:      werror.ErrorWithContextParams(context.Background(), "failed"),
+			wantCode: codes.Internal,
+			wantMsg:  "Default:Internal",
+		},
+		{
+			name:     "conflict",
+			err:      werror.ErrorWithContextParams(context.Background(), "failed", werror.Type(werror.DefaultConflict)),
+			wantCode: codes.Aborted,
+			wantMsg:  "Default:Conflict",
+		},
+		{
+			name:     "wrapped status error is preserved",
+			err:      werror.WrapWithContextParams(context.Background(), status.Error(codes.Unavailable, "unavailable"), "call failed"),
+			wantCode: codes.Unavailable,
+			wantMsg:  "call failed: rpc error: code = Unavailable desc = unavailable",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			st := werrorgrpc.ToStatus(currCase.err)
+			assert.Equal(t, currCase.wantCode, st.Code())
+			assert.Equal(t, currCase.wantMsg, st.Message())
+		})
+	}
+	assert.Nil(t, werrorgrpc.ToStatus(nil))
+}
+
+func TestFromStatus_NonWerrorStatus(t *testing.T) {
+	err := werrorgrpc.FromStatus(context.Background(), status.New(codes.NotFound, "user bob not found"))
+	assert.EqualError(t, err, "gRPC error status")
+	_, ok := werror.TypeFromError(err)
+	assert.False(t, ok)
+	code, safe := werror.ParamFromError(err, "grpcCode")
+	assert.Equal(t, "NotFound", code)
+	assert.True(t, safe)
+	msg, safe := werror.ParamFromError(err, "grpcMessage")
+	assert.Equal(t, "user bob not found", msg)
+	assert.False(t, safe)
+
+	assert.NoError(t, werrorgrpc.FromStatus(context.Background(), status.New(codes.OK, "")))
+}
+
+func TestInterceptors(t *testing.T) {
+	srvErr := werror.ErrorWithContextParams(context.Background(), "service not found",
+		werror.Type(testNotFoundType),
+		werror.SafeParam("service", "users"),
+	)
+	client := newTestClient(t, &healthServer{err: srvErr})
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "users"})
+		require.Error(t, err)
+		assert.EqualError(t, err, "gRPC call failed: Test:ServiceNotFound")
+		_, ok := werror.TypeFromError(err)
+		assert.False(t, ok)
+		remoteName, _ := werror.ParamFromError(err, "remoteErrorName")
+		assert.Equal(t, "Test:ServiceNotFound", remoteName)
+		assert.Equal(t, werror.InstanceIDFromError(srvErr), werror.InstanceIDFromError(err))
+		method, safe := werror.ParamFromError(err, "method")
+		assert.Equal(t, "/grpc.health.v1.Health/Check", method)
+		assert.True(t, safe)
+		service, _ := werror.ParamFromError(err, "service")
+		assert.Equal(t, "users", service)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "users"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, io.EOF))
+		remoteCode, _ := werror.ParamFromError(err, "remoteErrorCode")
+		assert.Equal(t, "NOT_FOUND", remoteCode)
+		method, _ := werror.ParamFromError(err, "method")
+		assert.Equal(t, "/grpc.health.v1.Health/Watch", method)
+	})
+}
+
+func newTestClient(t *testing.T, srv grpc_health_v1.HealthServer) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(werrorgrpc.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(werrorgrpc.StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(server, srv)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(werrorgrpc.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(werrorgrpc.StreamClientInterceptor()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
@@ -0,0 +1,124 @@
+// Package werrorio provides io.Reader, io.Writer, io.ReaderAt and io.Closer wrappers that convert the errors returned
+// by the wrapped values into werrors that store the position at which the error occurred.
+//
+// The returned errors store the operation ("read", "write" or "close"), the byte offset at which the operation started
+// and the number of bytes transferred by the failed operation as safe parameters. io.EOF is always returned unchanged
+// so that it can be compared directly, and all other errors are stored as the cause of the returned errors so that they
+// remain available to errors.Is and errors.As.
+package werrorio
+
+import (
+	"context"
+	"io"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// Option configures the wrappers returned by this package.
+type Option func(*config)
+
+type config struct {
+	name string
+}
+
+// Name returns an Option that stores the provided name as the "ioName" safe parameter of the returned errors. The name
+// should identify the stream being read or written (for example, "config file") and should not contain unsafe data
+// such as a full file path.
+func Name(name string) Option {
+	return func(cfg *config) {
+		cfg.name = name
+	}
+}
+
+func newConfig(options []Option) config {
+	var cfg config
+	for _, option := range options {
+		option(&cfg)
+	}
+	return cfg
+}
+
+func (cfg config) wrap(err error, op string, offset int64, n int, hasOffset bool) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	params := []werror.Param{werror.SafeParam("ioOperation", op)}
+	if hasOffset {
+		params = append(params,
+			werror.SafeParam("offset", offset),
+			werror.SafeParam("bytesTransferred", n),
+		)
+	}
+	if cfg.name != "" {
+		params = append(params, werror.SafeParam("ioName", cfg.name))
+	}
+	return werror.WrapWithContextParams(context.Background(), err, op+" failed", params...)
+}
+
+// NewReader returns an io.Reader that wraps the errors returned by r. The offset of the returned errors is the total
+// number of bytes read from r before the failed read.
+func NewReader(r io.Reader, options ...Option) io.Reader {
+	return &reader{r: r, cfg: newConfig(options)}
+}
+
+type reader struct {
+	r      io.Reader
+	cfg    config
+	offset int64
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	offset := r.offset
+	n, err := r.r.Read(p)
+	r.offset += int64(n)
+	return n, r.cfg.wrap(err, "read", offset, n, true)
+}
+
+// NewWriter returns an io.Writer that wraps the errors returned by w. The offset of the returned errors is the total
+// number of bytes written to w before the failed write.
+func NewWriter(w io.Writer, options ...Option) io.Writer {
+	return &writer{w: w, cfg: newConfig(options)}
+}
+
+type writer struct {
+	w      io.Writer
+	cfg    config
+	offset int64
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	offset := w.offset
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return n, w.cfg.wrap(err, "write", offset, n, true)
+}
+
+// NewReaderAt returns an io.ReaderAt that wraps the errors returned by r. The offset of the returned errors is the
+// offset provided to the failed ReadAt call. The returned io.ReaderAt is safe for concurrent use if r is.
+func NewReaderAt(r io.ReaderAt, options ...Option) io.ReaderAt {
+	return &readerAt{r: r, cfg: newConfig(options)}
+}
+
+type readerAt struct {
+	r   io.ReaderAt
+	cfg config
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.r.ReadAt(p, off)
+	return n, r.cfg.wrap(err, "read", off, n, true)
+}
+
+// NewCloser returns an io.Closer that wraps the errors returned by c. The returned errors do not store an offset.
+func NewCloser(c io.Closer, options ...Option) io.Closer {
+	return &closer{c: c, cfg: newConfig(options)}
+}
+
+type closer struct {
+	c   io.Closer
+	cfg config
+}
+
+func (c *closer) Close() error {
+	return c.cfg.wrap(c.c.Close(), "close", 0, 0, false)
+}
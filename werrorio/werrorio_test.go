@@ -0,0 +1,113 @@
+package werrorio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-error/werrorio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct {
+	limit int
+	n     int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n+len(p) > w.limit {
+		n := w.limit - w.n
+		w.n = w.limit
+		return n, io.ErrShortWrite
+	}
+	w.n += len(p)
+	return len(p), nil
+}
+
+type closeErrorCloser struct{}
+
+func (closeErrorCloser) Close() error {
+	return errors.New("close failed")
+}
+
+func TestReader(t *testing.T) {
+	r := werrorio.NewReader(io.MultiReader(strings.NewReader("abc"), errReader{err: io.ErrUnexpectedEOF}))
+	_, err := io.ReadAll(r)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	r = werrorio.NewReader(io.MultiReader(strings.NewReader("abcd"), errReader{err: errRead}), werrorio.Name("config file"))
+	data, err := io.ReadAll(r)
+	assert.Equal(t, "abcd", string(data))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errRead))
+	safe, _ := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{
+		"ioOperation":      "read",
+		"offset":           int64(4),
+		"bytesTransferred": 0,
+		"ioName":           "config file",
+	}, safe)
+
+	// io.EOF is returned unchanged
+	r = werrorio.NewReader(strings.NewReader(""))
+	_, err = r.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
+var errRead = errors.New("read failed")
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestWriter(t *testing.T) {
+	w := werrorio.NewWriter(&failingWriter{limit: 5})
+	_, err := w.Write([]byte("abc"))
+	require.NoError(t, err)
+	n, err := w.Write([]byte("defg"))
+	assert.Equal(t, 2, n)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, io.ErrShortWrite))
+	safe, _ := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{
+		"ioOperation":      "write",
+		"offset":           int64(3),
+		"bytesTransferred": 2,
+	}, safe)
+}
+
+func TestReaderAt(t *testing.T) {
+	r := werrorio.NewReaderAt(bytes.NewReader([]byte("abcdef")))
+	_, err := r.ReadAt(make([]byte, 2), 10)
+	// bytes.Reader returns io.EOF when the offset is past the end
+	assert.Equal(t, io.EOF, err)
+
+	r = werrorio.NewReaderAt(bytes.NewReader([]byte("abcdef")), werrorio.Name("index"))
+	_, err = r.ReadAt(make([]byte, 2), -1)
+	require.Error(t, err)
+	safe, _ := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{
+		"ioOperation":      "read",
+		"offset":           int64(-1),
+		"bytesTransferred": 0,
+		"ioName":           "index",
+	}, safe)
+}
+
+func TestCloser(t *testing.T) {
+	err := werrorio.NewCloser(closeErrorCloser{}).Close()
+	require.EqualError(t, err, "close failed: close failed")
+	safe, _ := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{"ioOperation": "close"}, safe)
+
+	assert.NoError(t, werrorio.NewCloser(io.NopCloser(nil)).Close())
+}
@@ -0,0 +1,28 @@
+module github.com/palantir/witchcraft-go-error/werrorotel
+
+go 1.21
+
+require (
+	github.com/palantir/witchcraft-go-error v1.34.0
+	github.com/stretchr/testify v1.9.0
+	go.opentelemetry.io/otel v1.29.0
+	go.opentelemetry.io/otel/sdk v1.29.0
+	go.opentelemetry.io/otel/trace v1.29.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/palantir/witchcraft-go-params v1.32.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.29.0 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// This module uses APIs of github.com/palantir/witchcraft-go-error that have not been released yet, so it is built
+// against the code in this repository and is not tagged. Before tagging it, require the first release that contains
+// these APIs and remove this directive.
+replace github.com/palantir/witchcraft-go-error => ../
@@ -0,0 +1,150 @@
+// Package werrorotel records werrors on OpenTelemetry spans.
+package werrorotel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ParamAttributePrefix is the prefix of the span event attributes created for the safe params of an error.
+	ParamAttributePrefix = "error.param."
+
+	exceptionEventName = "exception"
+
+	exceptionTypeKey       = attribute.Key("exception.type")
+	exceptionMessageKey    = attribute.Key("exception.message")
+	exceptionStacktraceKey = attribute.Key("exception.stacktrace")
+	errorTypeKey           = attribute.Key("error.type")
+	errorInstanceIDKey     = attribute.Key("error.instance_id")
+)
+
+// RecordError records the provided error on the span as an exception event and sets the status of the span to
+// codes.Error. Does nothing if err is nil or the span is not recording.
+//
+// The event has the following attributes:
+//
+//	exception.type         the name of the type declared by the error (see werror.TypeFromError) or, if the error
+//	                       does not declare a type, the Go type of its root cause
+//	exception.message      the messages of the werrors in the chain, joined by ": " (omitted if there are none)
+//	exception.stacktrace   the stack trace of the innermost werror in the chain
+//	error.type             the name of the declared type, if any
+//	error.instance_id      the instance ID of the error, if any
+//	error.param.<key>      the safe params of the error
+//
+// Unsafe params are never recorded. The Error() output of the error is not recorded either since the text of an error that
+// is not a werror may contain unsafe data. The status description of the span is the same as the exception message.
+func RecordError(span trace.Span, err error) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+	message := safeMessage(err)
+	var attrs []attribute.KeyValue
+	if message != "" {
+		attrs = append(attrs, exceptionMessageKey.String(message))
+	}
+	if errorType, ok := werror.TypeFromError(err); ok {
+		attrs = append(attrs,
+			exceptionTypeKey.String(errorType.Name()),
+			errorTypeKey.String(errorType.Name()),
+		)
+	} else {
+		attrs = append(attrs, exceptionTypeKey.String(fmt.Sprintf("%T", werror.RootCause(err))))
+	}
+	if instanceID := werror.InstanceIDFromError(err); instanceID != "" {
+		attrs = append(attrs, errorInstanceIDKey.String(instanceID))
+	}
+	if stacktrace := innermostStackTrace(err); stacktrace != "" {
+		attrs = append(attrs, exceptionStacktraceKey.String(stacktrace))
+	}
+	safe, _ := werror.ParamsFromError(err)
+	for k, v := range safe {
+		attrs = append(attrs, paramAttribute(ParamAttributePrefix+k, v))
+	}
+	span.AddEvent(exceptionEventName, trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, message)
+}
+
+// RecordErrorFromContext is like RecordError, but records the error on the span stored in the provided context.
+func RecordErrorFromContext(ctx context.Context, err error) {
+	RecordError(trace.SpanFromContext(ctx), err)
+}
+
+// WithSpanParams returns the provided error annotated with the trace ID and span ID of the span stored in the provided
+// context as the safe params "traceId" and "spanId", so that logs of the error can be correlated with the trace. Returns
+// err unchanged if it is nil or the context does not store a valid span context.
+func WithSpanParams(ctx context.Context, err error) error {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if err == nil || !spanContext.IsValid() {
+		return err
+	}
+	return werror.WithParams(err,
+		werror.SafeParam("traceId", spanContext.TraceID().String()),
+		werror.SafeParam("spanId", spanContext.SpanID().String()),
+	)
+}
+
+func paramAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+// safeMessage returns the non-empty messages of the werrors in the chain of err joined by ": ". The messages of errors
+// that are not werrors are omitted.
+func safeMessage(err error) string {
+	var messages []string
+	for currErr := err; currErr != nil; {
+		if werr, ok := currErr.(werror.Werror); ok && werr.Message() != "" {
+			messages = append(messages, werr.Message())
+		}
+		causer, ok := currErr.(werror.Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
+	}
+	return strings.Join(messages, ": ")
+}
+
+// innermostStackTrace returns the formatted stack trace of the innermost error in the chain of err that has a stack
+// trace.
+func innermostStackTrace(err error) string {
+	var stacktrace string
+	for currErr := err; currErr != nil; {
+		if tracer, ok := currErr.(werror.StackTracer); ok && tracer.StackTrace() != nil {
+			stacktrace = strings.TrimPrefix(fmt.Sprintf("%+v", tracer.StackTrace()), "\n")
+		}
+		causer, ok := currErr.(werror.Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
+	}
+	return stacktrace
+}
@@ -0,0 +1,98 @@
+package werrorotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-error/werrorotel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTracer() (*tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	exporter := tracetest.NewInMemoryExporter()
+	return exporter, sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+}
+
+func TestRecordError(t *testing.T) {
+	exporter, provider := newTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "getUser")
+
+	notFound := werror.MustErrorType(werror.CategoryNotFound, "Users:UserNotFound")
+	err := werror.WrapWithContextParams(ctx, errors.New("no rows for user@example.com"), "user not found",
+		werror.Type(notFound),
+		werror.SafeParam("userId", "user-1"),
+		werror.SafeParam("attempt", 2),
+		werror.SafeParam("cached", false),
+		werror.UnsafeParam("email", "user@example.com"),
+	)
+	werrorotel.RecordErrorFromContext(ctx, err)
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "user not found", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	event := spans[0].Events[0]
+	assert.Equal(t, "exception", event.Name)
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range event.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	assert.Equal(t, "Users:UserNotFound", attrs["exception.type"].AsString())
+	assert.Equal(t, "Users:UserNotFound", attrs["error.type"].AsString())
+	assert.Equal(t, werror.InstanceIDFromError(err), attrs["error.instance_id"].AsString())
+	assert.Equal(t, "user not found", attrs["exception.message"].AsString())
+	assert.Contains(t, attrs["exception.stacktrace"].AsString(), "werrorotel_test.TestRecordError")
+	assert.Equal(t, "user-1", attrs["error.param.userId"].AsString())
+	assert.Equal(t, int64(2), attrs["error.param.attempt"].AsInt64())
+	assert.Equal(t, false, attrs["error.param.cached"].AsBool())
+	assert.NotContains(t, attrs, attribute.Key("error.param.email"))
+}
+
+func TestRecordErrorWithoutType(t *testing.T) {
+	exporter, provider := newTracer()
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	werrorotel.RecordError(span, werror.Convert(errors.New("plain")))
+	werrorotel.RecordError(span, nil)
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events, 1)
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range spans[0].Events[0].Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	assert.Equal(t, "*errors.errorString", attrs["exception.type"].AsString())
+	assert.NotContains(t, attrs, attribute.Key("error.type"))
+	assert.Contains(t, attrs, attribute.Key("exception.stacktrace"))
+	assert.NotContains(t, attrs, attribute.Key("exception.message"))
+	assert.Empty(t, spans[0].Status.Description)
+}
+
+func TestWithSpanParams(t *testing.T) {
+	_, provider := newTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	err := werrorotel.WithSpanParams(ctx, werror.ErrorWithContextParams(ctx, "failed"))
+	safe, _ := werror.ParamsFromError(err)
+	assert.Equal(t, map[string]interface{}{
+		"traceId": span.SpanContext().TraceID().String(),
+		"spanId":  span.SpanContext().SpanID().String(),
+	}, safe)
+	assert.EqualError(t, err, "failed")
+
+	plain := werror.ErrorWithContextParams(context.Background(), "failed")
+	assert.Equal(t, plain, werrorotel.WithSpanParams(context.Background(), plain))
+	assert.Nil(t, werrorotel.WithSpanParams(ctx, nil))
+}
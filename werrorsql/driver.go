@@ -0,0 +1,149 @@
+// Package werrorsql provides a database/sql/driver wrapper that wraps the errors returned by a driver in werrors that
+// identify the failed operation and query.
+//
+// The wrapped errors include any wparams parameters stored in the context and store the operation ("exec", "query",
+// "prepare", "begin", "commit" or "rollback") and the query name provided using ContextWithQueryName as safe
+// parameters, and the SQL text and arguments as unsafe parameters.
+package werrorsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// DriverNamePrefix is the prefix of the names of the drivers registered by Register.
+const DriverNamePrefix = "werror-"
+
+type queryNameContextKey struct{}
+
+// ContextWithQueryName returns a context that stores the provided query name. The name is stored as a safe parameter
+// on the errors returned for operations performed using the context, so it should be a constant that identifies the
+// query rather than the query itself.
+func ContextWithQueryName(ctx context.Context, queryName string) context.Context {
+	return context.WithValue(ctx, queryNameContextKey{}, queryName)
+}
+
+// QueryNameFromContext returns the query name stored in the context, or the empty string if there is none.
+func QueryNameFromContext(ctx context.Context) string {
+	queryName, _ := ctx.Value(queryNameContextKey{}).(string)
+	return queryName
+}
+
+var registerMu sync.Mutex
+
+// Register registers a driver that wraps the driver registered with the provided name and returns the name of the
+// registered driver, which is the provided name prefixed with DriverNamePrefix. Calling Register multiple times with
+// the same name is safe and returns the same name.
+func Register(driverName string) (string, error) {
+	// registerMu ensures that concurrent calls do not both register the same driver, which panics
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	wrappedName := DriverNamePrefix + driverName
+	for _, registered := range sql.Drivers() {
+		if registered == wrappedName {
+			return wrappedName, nil
+		}
+	}
+	// sql.Open does not connect to the database, so it can be used to look up the registered driver
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return "", werror.WrapWithContextParams(context.Background(), err, "failed to look up driver", werror.SafeParam("driverName", driverName))
+	}
+	d := db.Driver()
+	_ = db.Close()
+	sql.Register(wrappedName, Wrap(d))
+	return wrappedName, nil
+}
+
+// Wrap returns a driver that wraps the errors returned by the provided driver.
+func Wrap(d driver.Driver) driver.Driver {
+	return &wrappedDriver{driver: d}
+}
+
+// WrapConnector returns a connector that wraps the errors returned by the connections opened by the provided connector.
+// It can be used with sql.OpenDB.
+func WrapConnector(c driver.Connector) driver.Connector {
+	return &wrappedConnector{connector: c, driver: Wrap(c.Driver())}
+}
+
+type wrappedDriver struct {
+	driver driver.Driver
+}
+
+var _ driver.DriverContext = (*wrappedDriver)(nil)
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, wrapErr(context.Background(), err, "open", "", nil)
+	}
+	return &wrappedConn{conn: conn}, nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, wrapErr(context.Background(), err, "open", "", nil)
+		}
+		return &wrappedConnector{connector: connector, driver: d}, nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+type wrappedConnector struct {
+	connector driver.Connector
+	driver    driver.Driver
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, wrapErr(ctx, err, "open", "", nil)
+	}
+	return &wrappedConn{conn: conn}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// wrapErr wraps the provided error unless it is one of the sentinel errors that database/sql compares against
+// directly.
+func wrapErr(ctx context.Context, err error, op, query string, args []driver.NamedValue) error {
+	if err == nil || err == driver.ErrSkip || err == driver.ErrRemoveArgument {
+		return err
+	}
+	params := []werror.Param{werror.SafeParam("sqlOperation", op)}
+	if queryName := QueryNameFromContext(ctx); queryName != "" {
+		params = append(params, werror.SafeParam("queryName", queryName))
+	}
+	if query != "" {
+		params = append(params, werror.UnsafeParam("sql", query))
+	}
+	if len(args) > 0 {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		params = append(params, werror.UnsafeParam("sqlArgs", values))
+	}
+	return werror.WrapWithContextParams(ctx, err, "sql "+op+" failed", params...)
+}
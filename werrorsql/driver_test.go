@@ -0,0 +1,286 @@
+package werrorsql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-error/werrorsql"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFake = errors.New("fake driver error")
+
+// fakeDriver is an in-memory driver that fails every statement whose query is "FAIL" and fails commits if failCommit
+// is set.
+type fakeDriver struct {
+	failCommit bool
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "BAD SYNTAX" {
+		return nil, errFake
+	}
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{driver: c.driver}, nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query == "FAIL" {
+		return nil, errFake
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.query == "FAIL" {
+		return nil, errFake
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+type fakeTx struct {
+	driver *fakeDriver
+}
+
+func (t *fakeTx) Commit() error {
+	if t.driver.failCommit {
+		return errFake
+	}
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	return nil
+}
+
+func TestDriver(t *testing.T) {
+	fake := &fakeDriver{}
+	sql.Register("werrorsql-fake", fake)
+	driverName, err := werrorsql.Register("werrorsql-fake")
+	require.NoError(t, err)
+	assert.Equal(t, "werror-werrorsql-fake", driverName)
+	again, err := werrorsql.Register("werrorsql-fake")
+	require.NoError(t, err)
+	assert.Equal(t, driverName, again)
+
+	db, err := sql.Open(driverName, "")
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx := werrorsql.ContextWithQueryName(wparams.ContextWithSafeParam(context.Background(), "requestId", "abc"), "getUser")
+
+	t.Run("successful operations", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, "INSERT", 1)
+		require.NoError(t, err)
+		rows, err := db.QueryContext(ctx, "SELECT")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+	})
+
+	for _, currCase := range []struct {
+		name   string
+		run    func() error
+		wantOp string
+		sql    string
+		args   []interface{}
+	}{
+		{
+			name: "exec",
+			run: func() error {
+				_, err := db.ExecContext(ctx, "FAIL", "secret", 2)
+				return err
+			},
+			wantOp: "exec",
+			sql:    "FAIL",
+			args:   []interface{}{"secret", int64(2)},
+		},
+		{
+			name: "query",
+			run: func() error {
+				_, err := db.QueryContext(ctx, "FAIL")
+				return err
+			},
+			wantOp: "query",
+			sql:    "FAIL",
+		},
+		{
+			name: "prepare",
+			run: func() error {
+				_, err := db.PrepareContext(ctx, "BAD SYNTAX")
+				return err
+			},
+			wantOp: "prepare",
+			sql:    "BAD SYNTAX",
+		},
+		{
+			name: "commit",
+			run: func() error {
+				fake.failCommit = true
+				defer func() {
+					fake.failCommit = false
+				}()
+				tx, err := db.BeginTx(ctx, nil)
+				if err != nil {
+					return err
+				}
+				return tx.Commit()
+			},
+			wantOp: "commit",
+		},
+		{
+			name: "begin with unsupported options",
+			run: func() error {
+				_, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+				return err
+			},
+			wantOp: "begin",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			err := currCase.run()
+			require.Error(t, err)
+			werr, ok := err.(werror.Werror)
+			require.True(t, ok, "error should be a werror: %v", err)
+			assert.Equal(t, "sql "+currCase.wantOp+" failed", werr.Message())
+
+			safe, unsafe := werror.ParamsFromError(err)
+			assert.Equal(t, map[string]interface{}{
+				"sqlOperation": currCase.wantOp,
+				"queryName":    "getUser",
+				"requestId":    "abc",
+			}, safe)
+			wantUnsafe := map[string]interface{}{}
+			if currCase.sql != "" {
+				wantUnsafe["sql"] = currCase.sql
+			}
+			if currCase.args != nil {
+				wantUnsafe["sqlArgs"] = currCase.args
+			}
+			assert.Equal(t, wantUnsafe, unsafe)
+		})
+	}
+
+	t.Run("driver errors are preserved", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, "FAIL")
+		assert.True(t, errors.Is(err, errFake))
+	})
+}
+
+func TestRegisterConcurrently(t *testing.T) {
+	sql.Register("werrorsql-concurrent", &fakeDriver{})
+	const goroutines = 16
+	var wg sync.WaitGroup
+	names := make([]string, goroutines)
+	errs := make([]error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			names[i], errs[i] = werrorsql.Register("werrorsql-concurrent")
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < goroutines; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "werror-werrorsql-concurrent", names[i])
+	}
+}
+
+// customValue is a type that is only supported by the connection-level checker of checkingDriver.
+type customValue struct {
+	value int64
+}
+
+// checkingDriver is a fakeDriver whose connections convert customValue arguments.
+type checkingDriver struct {
+	fakeDriver
+}
+
+func (d *checkingDriver) Open(name string) (driver.Conn, error) {
+	return &checkingConn{fakeConn: fakeConn{driver: &d.fakeDriver}}, nil
+}
+
+type checkingConn struct {
+	fakeConn
+}
+
+func (c *checkingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(customValue); ok {
+		nv.Value = v.value
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+func TestDriverUsesConnectionValueChecker(t *testing.T) {
+	sql.Register("werrorsql-checking", &checkingDriver{})
+	driverName, err := werrorsql.Register("werrorsql-checking")
+	require.NoError(t, err)
+	db, err := sql.Open(driverName, "")
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	_, err = db.Exec("INSERT", customValue{value: 1})
+	require.NoError(t, err)
+
+	stmt, err := db.Prepare("INSERT")
+	require.NoError(t, err)
+	defer func() {
+		_ = stmt.Close()
+	}()
+	_, err = stmt.Exec(customValue{value: 2})
+	require.NoError(t, err)
+}
@@ -0,0 +1,195 @@
+package werrortest
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+)
+
+// update is namespaced so that it does not conflict with the -update flag that many test packages define themselves.
+var update = flag.Bool("werrortest.update", false, "update the golden files used by werrortest.RequireGolden")
+
+// RenderOption configures Render and RequireGolden.
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	maskLineNumbers bool
+}
+
+// MaskLineNumbers replaces the line numbers of stack frames with "_" so that the output does not change when lines are
+// added to or removed from the source files.
+func MaskLineNumbers() RenderOption {
+	return func(cfg *renderConfig) {
+		cfg.maskLineNumbers = true
+	}
+}
+
+// Render returns a deterministic rendering of err that is suitable for comparison against golden files. Each error in
+// the chain is rendered on its own line, starting with the outermost error, as its message followed by its safe and
+// unsafe params sorted by key. The stack frames of each error are rendered below it. Frames are rendered using paths
+// relative to the root of the module that contains the current working directory (the package directory when run by
+// "go test") and using function names relative to the module path, and frames outside the module are dropped. Instance
+// IDs and creation times are not rendered.
+func Render(err error, options ...RenderOption) string {
+	cfg := &renderConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	var buf bytes.Buffer
+	for currErr := err; currErr != nil; {
+		werr, ok := currErr.(werror.Werror)
+		if !ok {
+			buf.WriteString(currErr.Error())
+			buf.WriteString("\n")
+			break
+		}
+		renderLevel(&buf, werr, cfg)
+		currErr = werr.Cause()
+	}
+	return buf.String()
+}
+
+// RequireGolden requires that the output of Render for err matches the contents of the provided golden file. If the
+// tests are run with the -werrortest.update flag, the golden file is written instead. The path of the golden file is relative to
+// the current working directory, and golden files are conventionally stored in the "testdata" directory of the package.
+//
+// Importing this package registers the -werrortest.update flag.
+func RequireGolden(t TestingT, err error, goldenFile string, options ...RenderOption) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	got := Render(err, options...)
+	if *update {
+		if writeErr := os.MkdirAll(filepath.Dir(goldenFile), 0755); writeErr != nil {
+			t.Errorf("failed to create directory for golden file %s: %v", goldenFile, writeErr)
+			t.FailNow()
+		}
+		if writeErr := os.WriteFile(goldenFile, []byte(got), 0644); writeErr != nil {
+			t.Errorf("failed to write golden file %s: %v", goldenFile, writeErr)
+			t.FailNow()
+		}
+		return
+	}
+	want, readErr := os.ReadFile(goldenFile)
+	if readErr != nil {
+		t.Errorf("failed to read golden file %s: %v\nrun the tests with -werrortest.update to create it", goldenFile, readErr)
+		t.FailNow()
+	}
+	if !assert.Equal(t, string(want), got, "rendered error does not match golden file %s; run the tests with -werrortest.update to update it", goldenFile) {
+		t.FailNow()
+	}
+}
+
+func renderLevel(buf *bytes.Buffer, err werror.Werror, cfg *renderConfig) {
+	safe, unsafe := levelParams(err)
+	var parts []string
+	if err.Message() != "" {
+		parts = append(parts, err.Message())
+	}
+	if len(safe) > 0 {
+		parts = append(parts, "safe{"+renderParams(safe)+"}")
+	}
+	if len(unsafe) > 0 {
+		parts = append(parts, "unsafe{"+renderParams(unsafe)+"}")
+	}
+	buf.WriteString(strings.Join(parts, " "))
+	buf.WriteString("\n")
+
+	root, modulePath := currentModule()
+	for _, frame := range stackFrames(err) {
+		if root == "" || !strings.HasPrefix(frame.File, root+"/") {
+			continue
+		}
+		file := strings.TrimPrefix(frame.File, root+"/")
+		if strings.HasPrefix(file, "vendor/") {
+			continue
+		}
+		line := fmt.Sprint(frame.Line)
+		if cfg.maskLineNumbers {
+			line = "_"
+		}
+		function := frame.Function
+		if modulePath != "" {
+			function = strings.TrimPrefix(function, modulePath+"/")
+		}
+		fmt.Fprintf(buf, "    %s (%s:%s)\n", function, file, line)
+	}
+}
+
+// levelParams returns the params that are visible at the level of err but not in its cause.
+func levelParams(err werror.Werror) (safe map[string]interface{}, unsafe map[string]interface{}) {
+	safe, unsafe = err.SafeParams(), err.UnsafeParams()
+	childSafe, childUnsafe := werror.ParamsFromError(err.Cause())
+	for k := range childSafe {
+		delete(safe, k)
+	}
+	for k := range childUnsafe {
+		delete(unsafe, k)
+	}
+	return safe, unsafe
+}
+
+func renderParams(params map[string]interface{}) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := params[k]
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			v = rv.Elem().Interface()
+		}
+		parts = append(parts, fmt.Sprintf("%s:%+v", k, v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+var (
+	moduleOnce sync.Once
+	moduleRoot string
+	moduleName string
+)
+
+// currentModule returns the root directory and the module path of the module that contains the current working
+// directory. Returns empty strings if the module cannot be determined.
+func currentModule() (root string, modulePath string) {
+	moduleOnce.Do(func() {
+		dir, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		for {
+			if f, err := os.Open(filepath.Join(dir, "go.mod")); err == nil {
+				moduleRoot = filepath.ToSlash(dir)
+				scanner := bufio.NewScanner(f)
+				for scanner.Scan() {
+					if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "module ") {
+						moduleName = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+						break
+					}
+				}
+				_ = f.Close()
+				return
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return
+			}
+			dir = parent
+		}
+	})
+	return moduleRoot, moduleName
+}
@@ -0,0 +1,55 @@
+package werrortest_test
+
+import (
+	"context"
+	"flag"
+	"io"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-error/werrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	value := "pointer"
+	err := werror.WrapWithContextParams(context.Background(),
+		werror.ErrorWithContextParams(context.Background(), "inner",
+			werror.SafeParam("b", 2),
+			werror.SafeParam("a", &value),
+			werror.UnsafeParam("secret", "value"),
+		),
+		"outer",
+		werror.SafeParam("c", 3),
+	)
+	assert.Equal(t, ""+
+		"outer safe{c:3}\n"+
+		"    werrortest_test.TestRender (werrortest/golden_test.go:_)\n"+
+		"inner safe{a:pointer, b:2} unsafe{secret:value}\n"+
+		"    werrortest_test.TestRender (werrortest/golden_test.go:_)\n",
+		werrortest.Render(err, werrortest.MaskLineNumbers()))
+}
+
+func TestRequireGolden(t *testing.T) {
+	werrortest.RequireGolden(t, newTestError(), "testdata/error.golden", werrortest.MaskLineNumbers())
+	if flag.Lookup("werrortest.update").Value.String() == "true" {
+		// the mismatch below would overwrite the golden file
+		return
+	}
+
+	failures := run(func(t werrortest.TestingT) {
+		werrortest.RequireGolden(t, io.EOF, "testdata/error.golden", werrortest.MaskLineNumbers())
+	})
+	if assert.Len(t, failures, 1) {
+		assert.Contains(t, failures[0], "rendered error does not match golden file testdata/error.golden; run the tests with -werrortest.update to update it")
+	}
+}
+
+// test packages commonly define an -update flag themselves: defining it must not conflict with the flag registered by
+// werrortest.
+var _ = flag.Bool("update", false, "update the golden files of this package")
+
+func TestRequireGoldenFlagDoesNotConflict(t *testing.T) {
+	assert.NotNil(t, flag.Lookup("update"))
+	assert.NotNil(t, flag.Lookup("werrortest.update"))
+}
@@ -0,0 +1,226 @@
+// Package werrortest provides testify-style assertions for errors created using the werror package. On failure, each
+// assertion prints the full output of werror.GenerateErrorString for the error, including all of its stack traces.
+//
+// The package also supports comparing errors against golden files using a deterministic rendering of the error (see
+// Render and RequireGolden).
+package werrortest
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	internalerrors "github.com/palantir/witchcraft-go-error/internal/errors"
+)
+
+// TestingT is the subset of testing.TB used by the assertions. It is compatible with require.TestingT.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	FailNow()
+}
+
+type tHelper interface {
+	Helper()
+}
+
+// RequireMessageChain requires that the messages of err and its causes are the provided messages, from the outermost
+// to the innermost error. Levels with an empty message, such as the ones created by werror.WithParams or
+// werror.Convert, are skipped. The message of an error in the chain that is not a werror.Werror is its Error() output.
+func RequireMessageChain(t TestingT, err error, messages ...string) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	got := messageChain(err)
+	if !reflect.DeepEqual(got, messages) {
+		fail(t, err, "unexpected message chain:\n\texpected: %q\n\tactual:   %q", messages, got)
+	}
+}
+
+// RequireSafeParam requires that err or any of its causes stores a safe param with the provided key and value.
+func RequireSafeParam(t TestingT, err error, key string, value interface{}) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireParam(t, err, key, value, true)
+}
+
+// RequireUnsafeParam requires that err or any of its causes stores an unsafe param with the provided key and value.
+func RequireUnsafeParam(t TestingT, err error, key string, value interface{}) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireParam(t, err, key, value, false)
+}
+
+// RequireNoParam requires that neither err nor any of its causes stores a safe or unsafe param with the provided key.
+func RequireNoParam(t TestingT, err error, key string) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	safe, unsafe := werror.ParamsFromError(err)
+	if value, ok := safe[key]; ok {
+		fail(t, err, "expected no param %q, but found safe param with value %#v", key, value)
+	}
+	if value, ok := unsafe[key]; ok {
+		fail(t, err, "expected no param %q, but found unsafe param with value %#v", key, value)
+	}
+}
+
+// RequireType requires that the type of err, as returned by werror.TypeFromError, is the provided type.
+func RequireType(t TestingT, err error, errorType werror.ErrorType) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	got, ok := werror.TypeFromError(err)
+	if !ok {
+		fail(t, err, "expected error type %s, but the error does not declare a type", errorType)
+	}
+	if got != errorType {
+		fail(t, err, "unexpected error type:\n\texpected: %s (%s)\n\tactual:   %s (%s)", errorType, errorType.Category(), got, got.Category())
+	}
+}
+
+// RequireCreatedAt requires that the innermost error in the chain of err that has a stack trace was created in the
+// provided file. The file matches if it is equal to the path of the file in which the error was created or to a
+// suffix of that path that starts at a path separator, so "file.go" and "pkg/file.go" both match "/src/pkg/file.go".
+func RequireCreatedAt(t TestingT, err error, file string) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	frame, ok := creationFrame(err)
+	if !ok {
+		fail(t, err, "expected error created in %s, but no error in the chain has a stack trace", file)
+	}
+	file = filepath.ToSlash(file)
+	if got := filepath.ToSlash(frame.File); got != file && !strings.HasSuffix(got, "/"+file) {
+		fail(t, err, "unexpected creation site:\n\texpected: %s\n\tactual:   %s:%d", file, got, frame.Line)
+	}
+}
+
+// RequireRootCause requires that the root cause of err, as returned by werror.RootCause, is the provided error or
+// wraps it as determined by errors.Is.
+func RequireRootCause(t TestingT, err error, rootCause error) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	if got := werror.RootCause(err); !errors.Is(got, rootCause) {
+		fail(t, err, "unexpected root cause:\n\texpected: %v\n\tactual:   %v", rootCause, got)
+	}
+}
+
+func requireParam(t TestingT, err error, key string, value interface{}, wantSafe bool) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	requireError(t, err)
+	safe, unsafe := werror.ParamsFromError(err)
+	want, other := safe, unsafe
+	if !wantSafe {
+		want, other = unsafe, safe
+	}
+	got, ok := want[key]
+	if !ok {
+		if otherValue, ok := other[key]; ok {
+			fail(t, err, "expected %s param %q, but found %s param with value %#v", safety(wantSafe), key, safety(!wantSafe), otherValue)
+		}
+		fail(t, err, "expected %s param %q, but the error does not have a param with that key", safety(wantSafe), key)
+	}
+	if !reflect.DeepEqual(got, value) {
+		fail(t, err, "unexpected value of %s param %q:\n\texpected: %#v (%T)\n\tactual:   %#v (%T)", safety(wantSafe), key, value, value, got, got)
+	}
+}
+
+func requireError(t TestingT, err error) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if err == nil {
+		t.Errorf("expected an error, but got nil")
+		t.FailNow()
+	}
+}
+
+func fail(t TestingT, err error, format string, args ...interface{}) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	t.Errorf("%s\n\nerror:\n%s", fmt.Sprintf(format, args...), werror.GenerateErrorString(err, true))
+	t.FailNow()
+}
+
+func safety(safe bool) string {
+	if safe {
+		return "safe"
+	}
+	return "unsafe"
+}
+
+func messageChain(err error) []string {
+	messages := []string{}
+	for currErr := err; currErr != nil; {
+		werr, ok := currErr.(werror.Werror)
+		if !ok {
+			return append(messages, currErr.Error())
+		}
+		if werr.Message() != "" {
+			messages = append(messages, werr.Message())
+		}
+		currErr = werr.Cause()
+	}
+	return messages
+}
+
+// creationFrame returns the first frame of the stack trace of the innermost error in the chain of err that has a
+// stack trace.
+func creationFrame(err error) (runtime.Frame, bool) {
+	var frame runtime.Frame
+	found := false
+	for currErr := err; currErr != nil; {
+		if frames := stackFrames(currErr); len(frames) > 0 {
+			frame, found = frames[0], true
+		}
+		causer, ok := currErr.(werror.Causer)
+		if !ok {
+			break
+		}
+		currErr = causer.Cause()
+	}
+	return frame, found
+}
+
+// stackFrames returns the frames of the stack trace stored on err itself, not including its causes.
+func stackFrames(err error) []runtime.Frame {
+	tracer, ok := err.(werror.StackTracer)
+	if !ok || tracer.StackTrace() == nil {
+		return nil
+	}
+	st, ok := tracer.StackTrace().(interface {
+		StackTrace() internalerrors.StackTrace
+	})
+	if !ok {
+		return nil
+	}
+	var pcs []uintptr
+	for _, f := range st.StackTrace() {
+		pcs = append(pcs, uintptr(f))
+	}
+	var frames []runtime.Frame
+	callersFrames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := callersFrames.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
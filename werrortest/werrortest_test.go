@@ -0,0 +1,194 @@
+package werrortest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-error/werrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+var testType = werror.MustErrorType(werror.CategoryNotFound, "Test:NotFound")
+
+// recordingT records the failures reported by the assertions. FailNow panics to stop the assertion in the same way
+// that testing.T.FailNow stops the test goroutine.
+type recordingT struct {
+	failures []string
+}
+
+type failNow struct{}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) FailNow() {
+	panic(failNow{})
+}
+
+func run(fn func(t werrortest.TestingT)) (failures []string) {
+	t := &recordingT{}
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(failNow); !ok {
+				panic(r)
+			}
+		}
+		failures = t.failures
+	}()
+	fn(t)
+	return t.failures
+}
+
+func newTestError() error {
+	ctx := context.Background()
+	inner := werror.WrapWithContextParams(ctx, io.ErrUnexpectedEOF, "failed to read", werror.SafeParam("offset", 10))
+	return werror.WrapWithContextParams(ctx, werror.WithParams(inner, werror.UnsafeParam("path", "/tmp/file")), "failed to load",
+		werror.Type(testType),
+	)
+}
+
+func TestAssertions(t *testing.T) {
+	err := newTestError()
+	for _, currCase := range []struct {
+		name    string
+		fn      func(t werrortest.TestingT)
+		wantErr string
+	}{
+		{
+			name: "message chain",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireMessageChain(t, err, "failed to load", "failed to read", "unexpected EOF")
+			},
+		},
+		{
+			name: "message chain mismatch",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireMessageChain(t, err, "failed to load")
+			},
+			wantErr: `unexpected message chain:
+	expected: ["failed to load"]
+	actual:   ["failed to load" "failed to read" "unexpected EOF"]`,
+		},
+		{
+			name: "safe param",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireSafeParam(t, err, "offset", 10)
+			},
+		},
+		{
+			name: "safe param with wrong value",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireSafeParam(t, err, "offset", int64(10))
+			},
+			wantErr: `unexpected value of safe param "offset":
+	expected: 10 (int64)
+	actual:   10 (int)`,
+		},
+		{
+			name: "safe param that is unsafe",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireSafeParam(t, err, "path", "/tmp/file")
+			},
+			wantErr: `expected safe param "path", but found unsafe param with value "/tmp/file"`,
+		},
+		{
+			name: "unsafe param",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireUnsafeParam(t, err, "path", "/tmp/file")
+			},
+		},
+		{
+			name: "missing unsafe param",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireUnsafeParam(t, err, "missing", "value")
+			},
+			wantErr: `expected unsafe param "missing", but the error does not have a param with that key`,
+		},
+		{
+			name: "no param",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireNoParam(t, err, "missing")
+			},
+		},
+		{
+			name: "no param that exists",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireNoParam(t, err, "offset")
+			},
+			wantErr: `expected no param "offset", but found safe param with value 10`,
+		},
+		{
+			name: "type",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireType(t, err, testType)
+			},
+		},
+		{
+			name: "wrong type",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireType(t, err, werror.DefaultInternal)
+			},
+			wantErr: `unexpected error type:
+	expected: Default:Internal (INTERNAL)
+	actual:   Test:NotFound (NOT_FOUND)`,
+		},
+		{
+			name: "created at",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireCreatedAt(t, err, "werrortest_test.go")
+				werrortest.RequireCreatedAt(t, err, "werrortest/werrortest_test.go")
+			},
+		},
+		{
+			name: "created at wrong file",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireCreatedAt(t, err, "other_test.go")
+			},
+			wantErr: `unexpected creation site:
+	expected: other_test.go
+	actual:   `,
+		},
+		{
+			name: "root cause",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireRootCause(t, err, io.ErrUnexpectedEOF)
+			},
+		},
+		{
+			name: "wrong root cause",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireRootCause(t, err, io.EOF)
+			},
+			wantErr: `unexpected root cause:
+	expected: EOF
+	actual:   unexpected EOF`,
+		},
+		{
+			name: "nil error",
+			fn: func(t werrortest.TestingT) {
+				werrortest.RequireSafeParam(t, nil, "offset", 10)
+			},
+			wantErr: "expected an error, but got nil",
+		},
+	} {
+		t.Run(currCase.name, func(t *testing.T) {
+			failures := run(currCase.fn)
+			if currCase.wantErr == "" {
+				assert.Empty(t, failures)
+				return
+			}
+			if assert.Len(t, failures, 1) {
+				assert.True(t, strings.HasPrefix(failures[0], currCase.wantErr), failures[0])
+				if currCase.wantErr != "expected an error, but got nil" {
+					// failures include the full error output with stacks
+					assert.Contains(t, failures[0], "\n\nerror:\n"+werror.GenerateErrorString(err, true))
+				}
+			}
+		})
+	}
+}